@@ -0,0 +1,226 @@
+package main
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	"ASCII/asciiart"
+)
+
+// maxBannerBytes caps the size of an uploaded banner file
+const maxBannerBytes = 64 << 10
+
+// bannerNamePattern limits uploaded banner names to simple file names
+var bannerNamePattern = regexp.MustCompile(`^[a-z0-9_-]{1,32}$`)
+
+// bannerUploadDir is the on-disk banner directory uploads are written to.
+// It is empty when banners are read from the embedded files, which cannot
+// be written.
+var bannerUploadDir string
+
+// adminToken is the bearer token the admin endpoints require. They are
+// disabled when it is empty.
+var adminToken string
+
+// bannerUploadMu serializes uploads so two writes of one name cannot
+// interleave
+var bannerUploadMu sync.Mutex
+
+// authorizeAdmin checks the request's bearer token against adminToken and
+// writes the error response if it does not match
+func authorizeAdmin(w http.ResponseWriter, r *http.Request) bool {
+	if adminToken == "" {
+		renderJSONError(w, "Admin endpoints are disabled: set ADMIN_TOKEN to enable them.", http.StatusServiceUnavailable)
+		return false
+	}
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	// Compare in constant time so the token cannot be guessed from response times
+	if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(adminToken)) != 1 {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="admin"`)
+		renderJSONError(w, "Unauthorized: please send the admin token as an Authorization: Bearer header.", http.StatusUnauthorized)
+		return false
+	}
+	return true
+}
+
+// uploadResponse is the JSON body returned for an accepted banner upload
+type uploadResponse struct {
+	Banner string `json:"banner"`
+	Height int    `json:"height"`
+}
+
+// adminBannersHandler serves /admin/banners and /upload-banner. It accepts a
+// multipart upload of a .txt banner file in the file field, named by the
+// name field, from a request carrying the admin token. The file is checked
+// as the banner loader would read it, written to the banner directory and
+// made available at once. An existing banner is only replaced with
+// overwrite=1.
+func adminBannersHandler(w http.ResponseWriter, r *http.Request) {
+	// Check if the request method is POST
+	if r.Method != "POST" {
+		renderJSONMethodNotAllowed(w, "POST")
+		return
+	}
+	if !authorizeAdmin(w, r) {
+		return
+	}
+	if bannerUploadDir == "" {
+		renderJSONError(w, "Banner uploads are disabled: set BANNER_DIR or -assets to an on-disk directory.", http.StatusServiceUnavailable)
+		return
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, maxBannerBytes+maxBodyBytes)
+	if err := r.ParseMultipartForm(maxBannerBytes + maxBodyBytes); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			renderJSONError(w, fmt.Sprintf("Banner file too large: uploads are limited to %d bytes.", maxBannerBytes), http.StatusRequestEntityTooLarge)
+			return
+		}
+		renderJSONError(w, "Invalid form data: banners must be uploaded as multipart/form-data", http.StatusBadRequest)
+		return
+	}
+	name := r.FormValue("name")
+	if !bannerNamePattern.MatchString(name) || name == randomBanner {
+		renderJSONError(w, fmt.Sprintf("Invalid banner name %q: please use up to 32 lowercase letters, digits, dashes or underscores.", name), http.StatusBadRequest)
+		return
+	}
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		renderJSONError(w, "Missing file: please upload the banner as the file field.", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+	if header.Size > maxBannerBytes {
+		renderJSONError(w, fmt.Sprintf("Banner file too large: uploads are limited to %d bytes.", maxBannerBytes), http.StatusRequestEntityTooLarge)
+		return
+	}
+	content, err := io.ReadAll(file)
+	if err != nil {
+		renderJSONError(w, "Internal Server Error: Failed to read the uploaded file", http.StatusInternalServerError)
+		return
+	}
+	font, err := checkBannerFile(content)
+	if err != nil {
+		renderJSONError(w, "Invalid banner file: "+err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	bannerUploadMu.Lock()
+	defer bannerUploadMu.Unlock()
+	if isSupportedBanner(name) && r.FormValue("overwrite") != "1" {
+		renderJSONError(w, fmt.Sprintf("Banner %q already exists: set overwrite=1 to replace it.", name), http.StatusConflict)
+		return
+	}
+	if err := writeBannerFile(filepath.Join(bannerUploadDir, name+".txt"), content); err != nil {
+		renderJSONError(w, "Internal Server Error: Failed to save the banner file", http.StatusInternalServerError)
+		return
+	}
+	// Mark the banner as uploaded, so it is still listed as one after a restart
+	if err := os.WriteFile(filepath.Join(bannerUploadDir, name+asciiart.UploadMarkerExt), nil, 0o644); err != nil {
+		renderJSONError(w, "Internal Server Error: Failed to save the banner file", http.StatusInternalServerError)
+		return
+	}
+	// Cache the new font before listing it, so no request sees a stale copy
+	asciiart.StoreBanner(name, font, int64(len(content)))
+	if _, err := rescanBanners(); err != nil {
+		renderJSONError(w, "Internal Server Error: Failed to scan banner directory", http.StatusInternalServerError)
+		return
+	}
+	renderJSON(w, uploadResponse{Banner: name, Height: font.Height}, http.StatusCreated)
+}
+
+// reloadResponse is the JSON body returned by /admin/reload
+type reloadResponse struct {
+	Reloaded  bool              `json:"reloaded"`
+	Banners   []string          `json:"banners"`          // banners that parsed
+	Templates []string          `json:"templates"`        // template files that parsed
+	Errors    map[string]string `json:"errors,omitempty"` // keyed by banner name or template file
+}
+
+// adminReloadHandler re-reads every banner and template file and swaps
+// them in together. If any file fails to parse, nothing is replaced and
+// the errors are returned with a 422, so a broken file never leaves the
+// server half reloaded. The request must carry the admin token.
+func adminReloadHandler(w http.ResponseWriter, r *http.Request) {
+	// Check if the request method is POST
+	if r.Method != "POST" {
+		renderJSONMethodNotAllowed(w, "POST")
+		return
+	}
+	if !authorizeAdmin(w, r) {
+		return
+	}
+	// Hold off uploads so none is lost between reading and swapping the banners
+	bannerUploadMu.Lock()
+	defer bannerUploadMu.Unlock()
+	banners, bannerProblems, err := asciiart.ReadBanners()
+	if err != nil {
+		renderJSONError(w, "Internal Server Error: Failed to scan banner directory", http.StatusInternalServerError)
+		return
+	}
+	parsed, templateProblems := parseTemplates()
+
+	response := reloadResponse{Banners: banners.Names(), Errors: make(map[string]string)}
+	for banner, err := range bannerProblems {
+		response.Errors[banner] = err.Error()
+	}
+	for _, file := range templateFiles {
+		if err := templateProblems[file]; err != nil {
+			response.Errors[file] = err.Error()
+			continue
+		}
+		response.Templates = append(response.Templates, file)
+	}
+	if len(response.Errors) > 0 {
+		for name, msg := range response.Errors {
+			log.Printf("Reload failed: %s: %s", name, msg)
+		}
+		renderJSON(w, response, http.StatusUnprocessableEntity)
+		return
+	}
+
+	asciiart.UseBanners(banners)
+	setSupportedBanners(banners.Names())
+	useTemplates(parsed)
+	response.Reloaded = true
+	log.Printf("Reloaded %d banners and %d templates", len(response.Banners), len(response.Templates))
+	renderJSON(w, response, http.StatusOK)
+}
+
+// checkBannerFile checks that content is a .txt banner of printable ASCII
+// that the banner loader accepts, and returns the parsed font
+func checkBannerFile(content []byte) (asciiart.Font, error) {
+	for i, b := range content {
+		if (b < ' ' || b > '~') && b != '\n' && b != '\r' {
+			return asciiart.Font{}, fmt.Errorf("byte %d is %q, but banners may only contain printable ASCII", i+1, b)
+		}
+	}
+	return asciiart.LoadFont(bytes.NewReader(content))
+}
+
+// writeBannerFile writes content to path through a temporary file, so the
+// banner loader never reads a half-written banner
+func writeBannerFile(path string, content []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,261 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/fs"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"ASCII/asciiart"
+)
+
+// testAdminToken is the admin token set by useAdminToken
+const testAdminToken = "s3cret"
+
+// useAdminToken enables the admin endpoints with testAdminToken for the rest of the test
+func useAdminToken(t *testing.T) {
+	t.Helper()
+	previous := adminToken
+	adminToken = testAdminToken
+	t.Cleanup(func() { adminToken = previous })
+}
+
+// useBannerDir serves the banners from a temporary directory holding the
+// given files, as with BANNER_DIR, and returns the directory
+func useBannerDir(t *testing.T, files map[string][]byte) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), content, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	useBanners(t, os.DirFS(dir))
+	if _, err := rescanBanners(); err != nil {
+		t.Fatal(err)
+	}
+	previous := bannerUploadDir
+	bannerUploadDir = dir
+	t.Cleanup(func() { bannerUploadDir = previous })
+	return dir
+}
+
+// embeddedBanner returns the content of a banner shipped with the server
+func embeddedBanner(t *testing.T, name string) []byte {
+	t.Helper()
+	content, err := fs.ReadFile(embeddedAssets, "ART/"+name+".txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return content
+}
+
+// serveAdmin sends a request with the given bearer token, or none when it
+// is empty, through the router
+func serveAdmin(req *http.Request, token string) *httptest.ResponseRecorder {
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	Serverouter(rec, req)
+	return rec
+}
+
+// uploadRequest builds a multipart banner upload of content named name,
+// with any extra form fields
+func uploadRequest(t *testing.T, name string, content []byte, fields map[string]string) *http.Request {
+	t.Helper()
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	form.WriteField("name", name)
+	for key, value := range fields {
+		form.WriteField(key, value)
+	}
+	file, err := form.CreateFormFile("file", name+".txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.Write(content)
+	form.Close()
+	req := httptest.NewRequest("POST", "/admin/banners", &body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	return req
+}
+
+func TestBannerUpload(t *testing.T) {
+	useAdminToken(t)
+	dir := useBannerDir(t, map[string][]byte{"standard.txt": embeddedBanner(t, "standard")})
+	shadow := embeddedBanner(t, "shadow")
+	malformed := bytes.Replace(shadow, []byte("\n\n"), []byte("\n"), 1)
+	tests := []struct {
+		name       string
+		banner     string
+		content    []byte
+		fields     map[string]string
+		wantStatus int
+		wantBody   string
+	}{
+		{name: "invalid name", banner: "../evil", content: shadow, wantStatus: http.StatusBadRequest, wantBody: "Invalid banner name"},
+		{name: "reserved name", banner: randomBanner, content: shadow, wantStatus: http.StatusBadRequest, wantBody: "Invalid banner name"},
+		{name: "too large", banner: "big", content: bytes.Repeat([]byte("a"), maxBannerBytes+1), wantStatus: http.StatusRequestEntityTooLarge, wantBody: "Banner file too large"},
+		{name: "malformed", banner: "broken", content: malformed, wantStatus: http.StatusUnprocessableEntity, wantBody: "Invalid banner file: glyph for"},
+		{name: "existing banner", banner: "standard", content: shadow, wantStatus: http.StatusConflict, wantBody: "set overwrite=1"},
+		{name: "new banner", banner: "my-shadow", content: shadow, wantStatus: http.StatusCreated, wantBody: `"banner":"my-shadow"`},
+		{name: "overwrite", banner: "standard", content: shadow, fields: map[string]string{"overwrite": "1"}, wantStatus: http.StatusCreated, wantBody: `"banner":"standard"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serveAdmin(uploadRequest(t, tt.banner, tt.content, tt.fields), testAdminToken)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("upload returned %d, want %d\n%s", rec.Code, tt.wantStatus, rec.Body)
+			}
+			if !strings.Contains(rec.Body.String(), tt.wantBody) {
+				t.Errorf("body does not contain %q:\n%s", tt.wantBody, rec.Body)
+			}
+		})
+	}
+
+	// Refused uploads leave nothing behind
+	for _, name := range []string{"big.txt", "broken.txt"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			t.Errorf("%s was written for a refused upload", name)
+		}
+	}
+	// Accepted banners can be used at once
+	want, err := asciiart.Generate(loadEmbeddedFont(t, "shadow"), []string{"Hi"}, asciiart.DefaultOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, banner := range []string{"my-shadow", "standard"} {
+		rec := serve("POST", "/ascii-art", "text=Hi&format=plain&banner="+banner)
+		if rec.Code != http.StatusOK || rec.Body.String() != want {
+			t.Errorf("art in the uploaded %s banner returned %d:\n%s\nwant\n%s", banner, rec.Code, rec.Body, want)
+		}
+	}
+}
+
+func TestBannerUploadEmbedded(t *testing.T) {
+	useAdminToken(t)
+	// bannerUploadDir is empty while the banners are the embedded copies
+	rec := serveAdmin(uploadRequest(t, "new", embeddedBanner(t, "shadow"), nil), testAdminToken)
+	if rec.Code != http.StatusServiceUnavailable || !strings.Contains(rec.Body.String(), "Banner uploads are disabled") {
+		t.Errorf("upload to the embedded banners returned %d, want %d\n%s", rec.Code, http.StatusServiceUnavailable, rec.Body)
+	}
+	if isSupportedBanner("new") {
+		t.Error("the refused banner is listed")
+	}
+}
+
+// loadEmbeddedFont parses a banner shipped with the server
+func loadEmbeddedFont(t *testing.T, name string) asciiart.Font {
+	t.Helper()
+	font, err := asciiart.LoadFont(bytes.NewReader(embeddedBanner(t, name)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return font
+}
+
+func TestAdminReloadKeepsBanners(t *testing.T) {
+	useAdminToken(t)
+	dir := useBannerDir(t, map[string][]byte{
+		"standard.txt": embeddedBanner(t, "standard"),
+		"shadow.txt":   embeddedBanner(t, "shadow"),
+	})
+	want := serve("POST", "/ascii-art", "text=Hi&format=plain&banner=shadow").Body.String()
+
+	// Drop one banner and add a broken one: the reload must change nothing
+	if err := os.Remove(filepath.Join(dir, "shadow.txt")); err != nil {
+		t.Fatal(err)
+	}
+	broken := bytes.Replace(embeddedBanner(t, "thinkertoy"), []byte("\n\n"), []byte("\n"), 1)
+	if err := os.WriteFile(filepath.Join(dir, "broken.txt"), broken, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	rec := serveAdmin(httptest.NewRequest("POST", "/admin/reload", nil), testAdminToken)
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("reload returned %d, want %d\n%s", rec.Code, http.StatusUnprocessableEntity, rec.Body)
+	}
+	var response reloadResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatal(err)
+	}
+	if response.Reloaded || response.Errors["broken"] == "" || len(response.Errors) != 1 {
+		t.Errorf("reload response = %+v, want only broken reported and nothing reloaded", response)
+	}
+	if !isSupportedBanner("shadow") || isSupportedBanner("broken") {
+		t.Error("the failed reload changed the listed banners")
+	}
+	if rec := serve("POST", "/ascii-art", "text=Hi&format=plain&banner=shadow"); rec.Code != http.StatusOK || rec.Body.String() != want {
+		t.Errorf("shadow after the failed reload returned %d:\n%s\nwant\n%s", rec.Code, rec.Body, want)
+	}
+
+	// Once the broken banner is gone the reload goes through
+	if err := os.Remove(filepath.Join(dir, "broken.txt")); err != nil {
+		t.Fatal(err)
+	}
+	rec = serveAdmin(httptest.NewRequest("POST", "/admin/reload", nil), testAdminToken)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("reload returned %d, want %d\n%s", rec.Code, http.StatusOK, rec.Body)
+	}
+	if isSupportedBanner("shadow") || !isSupportedBanner("standard") {
+		t.Error("the reload did not replace the listed banners")
+	}
+}
+
+func TestAdminAuth(t *testing.T) {
+	tests := []struct {
+		name       string
+		adminToken string
+		token      string
+		wantStatus int
+	}{
+		{name: "disabled", adminToken: "", token: testAdminToken, wantStatus: http.StatusServiceUnavailable},
+		{name: "no token", adminToken: testAdminToken, token: "", wantStatus: http.StatusUnauthorized},
+		{name: "wrong token", adminToken: testAdminToken, token: "guess", wantStatus: http.StatusUnauthorized},
+		{name: "right token", adminToken: testAdminToken, token: testAdminToken, wantStatus: http.StatusOK},
+	}
+	for _, target := range []string{"/admin/reload", "/api/banners?rescan=1"} {
+		for _, tt := range tests {
+			t.Run(target+" "+tt.name, func(t *testing.T) {
+				previous := adminToken
+				adminToken = tt.adminToken
+				t.Cleanup(func() { adminToken = previous })
+				method := "POST"
+				if strings.HasPrefix(target, "/api/") {
+					method = "GET"
+				}
+				rec := serveAdmin(httptest.NewRequest(method, target, nil), tt.token)
+				if rec.Code != tt.wantStatus {
+					t.Fatalf("%s returned %d, want %d\n%s", target, rec.Code, tt.wantStatus, rec.Body)
+				}
+				challenge := rec.Header().Get("WWW-Authenticate")
+				if (tt.wantStatus == http.StatusUnauthorized) != (challenge != "") {
+					t.Errorf("WWW-Authenticate = %q for status %d", challenge, rec.Code)
+				}
+			})
+		}
+	}
+}
+
+func TestAdminReloadInvalidBanner(t *testing.T) {
+	useAdminToken(t)
+	broken := bytes.Replace(embeddedBanner(t, "shadow"), []byte("\n\n"), []byte("\n"), 1)
+	useBannerDir(t, map[string][]byte{"standard.txt": embeddedBanner(t, "standard")})
+	if err := os.WriteFile(filepath.Join(bannerUploadDir, "broken.txt"), broken, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	rec := serveAdmin(httptest.NewRequest("POST", "/admin/reload", nil), testAdminToken)
+	if rec.Code != http.StatusUnprocessableEntity || !strings.Contains(rec.Body.String(), `"broken":"banner \"broken\": glyph for`) {
+		t.Errorf("reload returned %d, want %d with the glyph error\n%s", rec.Code, http.StatusUnprocessableEntity, rec.Body)
+	}
+	if !isSupportedBanner("standard") || isSupportedBanner("broken") {
+		t.Error("the failed reload changed the listed banners")
+	}
+}
@@ -0,0 +1,317 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"mime"
+	"net/http"
+	"strings"
+
+	"ASCII/asciiart"
+)
+
+// apiRequest is the JSON body accepted by the ASCII art API
+type apiRequest struct {
+	Text        string `json:"text"`
+	Banner      string `json:"banner"`
+	Unknown     string `json:"unknown"`
+	Align       string `json:"align"`
+	TabWidth    *int   `json:"tabwidth"`
+	Width       int    `json:"width"`
+	Wrap        int    `json:"wrap"`
+	Border      string `json:"border"`
+	Spacing     int    `json:"spacing"`
+	LineSpacing int    `json:"linespacing"`
+	Trim        bool   `json:"trim"`
+	Transform   string `json:"transform"`
+	// Color colors the art for letters, or all of the art when letters is
+	// empty, as ANSI escape sequences or, with colormode "html", HTML spans
+	Color     string `json:"color"`
+	Letters   string `json:"letters"`
+	ColorMode string `json:"colormode"`
+	Escape    bool   `json:"escape"`
+	Case      string `json:"case"`
+	// Compare renders the text in every banner instead of the one in Banner
+	Compare bool `json:"compare"`
+}
+
+// apiResponse is the JSON body returned on successful generation
+type apiResponse struct {
+	Art    string `json:"art"`
+	Result string `json:"result"` // the same art, kept for clients written before art
+	Banner string `json:"banner"`
+	Lines  int    `json:"lines"` // rendered lines of art after wrapping
+}
+
+// bannersResponse is the JSON body listing the available banners
+type bannersResponse struct {
+	Banners []string     `json:"banners"`
+	Details []bannerInfo `json:"details,omitempty"`
+}
+
+// bannerInfo describes a single banner font
+type bannerInfo struct {
+	Name       string `json:"name"`
+	Height     int    `json:"height"`
+	SpaceWidth int    `json:"spaceWidth"`
+	MinWidth   int    `json:"minWidth"`
+	MaxWidth   int    `json:"maxWidth"`
+	Size       int64  `json:"size"`
+	Source     string `json:"source"` // builtin, uploaded or custom
+}
+
+// apiError is the JSON body returned when a request fails
+type apiError struct {
+	Error      string        `json:"error"`
+	Code       int           `json:"code"`
+	Characters []invalidChar `json:"characters,omitempty"`
+}
+
+// invalidChar describes a character in the text that cannot be rendered
+type invalidChar struct {
+	Char     string `json:"char"`
+	Position int    `json:"position"`
+}
+
+// asciiArtAPIHandler processes JSON requests for ASCII art generation
+func asciiArtAPIHandler(w http.ResponseWriter, r *http.Request) {
+	// Check if the request method is POST
+	if r.Method != "POST" {
+		renderJSONMethodNotAllowed(w, "POST")
+		return
+	}
+	// Only JSON request bodies are accepted
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType != "application/json" {
+		renderJSONError(w, "Unsupported content type: requests must be application/json", http.StatusUnsupportedMediaType)
+		return
+	}
+	// Decode the JSON body and validate input
+	var req apiRequest
+	r.Body = http.MaxBytesReader(w, r.Body, maxBodyBytes)
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			renderJSONError(w, bodyTooLargeMessage(), http.StatusRequestEntityTooLarge)
+			return
+		}
+		renderJSONError(w, "Invalid JSON body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	req.Text = asciiart.NormalizeLineEndings(req.Text)
+	// Optionally turn literal \n sequences into line breaks
+	if req.Escape {
+		unescaped, err := asciiart.Unescape(req.Text)
+		if err != nil {
+			renderJSONError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		req.Text = unescaped
+	}
+	// Change the case before validation so errors refer to the rendered text
+	text, err := asciiart.ChangeCase(req.Text, req.Case)
+	if err != nil {
+		renderJSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	req.Text = text
+	if req.Text == "" {
+		renderJSONError(w, "Missing text: please provide the text for ASCII art generation.", http.StatusBadRequest)
+		return
+	}
+	if textTooLong(req.Text) {
+		renderJSONError(w, textTooLongMessage(), http.StatusBadRequest)
+		return
+	}
+	if msg := checkLineLimits(req.Text); msg != "" {
+		renderJSONError(w, msg, http.StatusBadRequest)
+		return
+	}
+	if req.Banner == "" && !req.Compare {
+		renderJSONError(w, "Missing banner: please select a banner for ASCII art generation.", http.StatusBadRequest)
+		return
+	}
+	// Pick a banner for a request asking for a random one
+	if req.Banner == randomBanner {
+		req.Banner = pickRandomBanner()
+		w.Header().Set("X-Banner-Used", req.Banner)
+	}
+	// Unknown banners are a missing resource for API clients
+	if req.Banner != "" && !isSupportedBanner(req.Banner) {
+		renderJSONError(w, unsupportedBannerMessage(), http.StatusNotFound)
+		return
+	}
+
+	opts := asciiart.Options{Unknown: req.Unknown, Align: req.Align, TabWidth: tabWidth, Width: req.Width, Wrap: req.Wrap, Border: req.Border, Spacing: req.Spacing, LineSpacing: req.LineSpacing, Trim: req.Trim, Transform: req.Transform}
+	if req.TabWidth != nil {
+		opts.TabWidth = *req.TabWidth
+	}
+	limitArtWidth(&opts)
+	if err := opts.Validate(); err != nil {
+		renderJSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if req.ColorMode != "" && req.ColorMode != asciiart.ColorModeANSI && req.ColorMode != asciiart.ColorModeHTML {
+		renderJSONError(w, "Invalid colormode: please use "+asciiart.ColorModeANSI+" or "+asciiart.ColorModeHTML+".", http.StatusBadRequest)
+		return
+	}
+
+	// Compare the text in every banner; coloring does not apply
+	if req.Compare {
+		comparisons, err := compareBanners(req.Text, opts)
+		if err != nil {
+			msg, status := compareFailure(err)
+			renderJSONError(w, msg, status)
+			return
+		}
+		renderJSON(w, compareResponse{Results: comparisonResults(comparisons)}, http.StatusOK)
+		return
+	}
+
+	// Generate the ASCII art with the same code path as the form handler
+	result, err := renderBannerArt([]string{req.Banner}, req.Text, opts)
+	if err != nil {
+		var invalid *asciiart.InvalidTextError
+		if errors.As(err, &invalid) {
+			renderInvalidTextError(w, invalid)
+		} else {
+			msg, status := renderFailure(err)
+			renderJSONError(w, msg, status)
+		}
+		return
+	}
+	if req.Color != "" {
+		result, err = colorAPIResult(req, opts)
+		if err != nil {
+			msg, status := renderFailure(err)
+			renderJSONError(w, msg, status)
+			return
+		}
+	}
+	renderJSON(w, apiResponse{Art: result, Result: result, Banner: req.Banner, Lines: visualLines(req.Banner, req.Text, opts)}, http.StatusOK)
+}
+
+// colorAPIResult renders the art for an API request in the requested color mode
+func colorAPIResult(req apiRequest, opts asciiart.Options) (string, error) {
+	banners, lines := []string{req.Banner}, asciiart.SplitLines(req.Text)
+	if req.ColorMode == asciiart.ColorModeHTML {
+		art, err := asciiart.RenderHighlighted(banners, lines, opts, req.Letters, req.Color)
+		return string(art), err
+	}
+	return asciiart.RenderANSI(banners, lines, opts, req.Letters, req.Color)
+}
+
+// visualLines counts the lines of text after wrapping, which is the number
+// of blocks of rows in the rendered art
+func visualLines(banner, text string, opts asciiart.Options) int {
+	lines := asciiart.SplitLines(text)
+	font, err := asciiart.LoadBanner(banner)
+	if err != nil {
+		return len(lines)
+	}
+	fonts := make([]asciiart.Font, len(lines))
+	for i := range fonts {
+		fonts[i] = font
+	}
+	_, wrapped := asciiart.WrapLines(fonts, lines, opts)
+	return len(wrapped)
+}
+
+// validateBannerAPIHandler reports on the structure of a candidate .txt
+// banner file sent as the request body, without saving it
+func validateBannerAPIHandler(w http.ResponseWriter, r *http.Request) {
+	// Check if the request method is POST
+	if r.Method != "POST" {
+		renderJSONMethodNotAllowed(w, "POST")
+		return
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, maxBannerBytes)
+	report, err := asciiart.CheckFont(r.Body)
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			renderJSONError(w, fmt.Sprintf("Banner file too large: banners are limited to %d bytes.", maxBannerBytes), http.StatusRequestEntityTooLarge)
+			return
+		}
+		renderJSONError(w, "Invalid request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	renderJSON(w, report, http.StatusOK)
+}
+
+// bannersAPIHandler lists the banners discovered in the ART directory.
+// ?rescan=1 re-reads and re-parses the directory first, for requests
+// carrying the admin token, and ?details=1 adds per-banner info.
+func bannersAPIHandler(w http.ResponseWriter, r *http.Request) {
+	// Check if the request method is GET
+	if r.Method != "GET" {
+		renderJSONMethodNotAllowed(w, "GET")
+		return
+	}
+	if r.URL.Query().Get("rescan") == "1" {
+		if !authorizeAdmin(w, r) {
+			return
+		}
+		bannerUploadMu.Lock()
+		_, err := rescanBanners()
+		bannerUploadMu.Unlock()
+		if err != nil {
+			log.Printf("Error rescanning banners: %v", err)
+			renderJSONError(w, "Internal Server Error: Failed to scan banner directory", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	response := bannersResponse{Banners: availableBanners()}
+	if r.URL.Query().Get("details") == "1" {
+		for _, banner := range response.Banners {
+			info, err := asciiart.BannerDetails(banner)
+			if err != nil {
+				// A banner changed on disk since the scan is still listed, just without details
+				continue
+			}
+			response.Details = append(response.Details, bannerInfo{Name: banner, Height: info.Height, SpaceWidth: info.SpaceWidth, MinWidth: info.MinWidth, MaxWidth: info.MaxWidth, Size: info.Size, Source: info.Source})
+		}
+	}
+	renderJSON(w, response, http.StatusOK)
+}
+
+// healthHandler reports that the process is up without touching fonts or templates
+func healthHandler(w http.ResponseWriter, r *http.Request) {
+	// Check if the request method is GET
+	if r.Method != "GET" {
+		renderJSONMethodNotAllowed(w, "GET")
+		return
+	}
+	renderJSON(w, map[string]string{"status": "ok"}, http.StatusOK)
+}
+
+// renderJSON writes a value as a JSON response with the given status code
+func renderJSON(w http.ResponseWriter, v any, statusCode int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("Error encoding JSON response: %v", err)
+	}
+}
+
+// renderJSONError writes an error message as a JSON response
+func renderJSONError(w http.ResponseWriter, errMsg string, statusCode int) {
+	renderJSON(w, apiError{Error: errMsg, Code: statusCode}, statusCode)
+}
+
+// renderInvalidTextError writes a 400 response listing each character that cannot be rendered
+func renderInvalidTextError(w http.ResponseWriter, invalid *asciiart.InvalidTextError) {
+	body := apiError{Error: invalid.Error(), Code: http.StatusBadRequest}
+	for _, c := range invalid.Chars {
+		body.Characters = append(body.Characters, invalidChar{Char: string(c.Char), Position: c.Position})
+	}
+	renderJSON(w, body, http.StatusBadRequest)
+}
+
+// renderJSONMethodNotAllowed writes a 405 JSON error listing the methods the route accepts
+func renderJSONMethodNotAllowed(w http.ResponseWriter, allowed ...string) {
+	w.Header().Set("Allow", strings.Join(allowed, ", "))
+	renderJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+}
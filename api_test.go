@@ -0,0 +1,83 @@
+package main
+
+import (
+	"encoding/json"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"testing/fstest"
+
+	"ASCII/asciiart"
+)
+
+func TestBannersRescan(t *testing.T) {
+	standard, err := fs.ReadFile(embeddedAssets, "ART/standard.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	boxes, err := os.ReadFile("asciiart/testdata/boxes.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fsys := fstest.MapFS{"standard.txt": &fstest.MapFile{Data: standard}}
+	useBanners(t, fsys)
+
+	// Rescanning needs the admin token
+	if rec := serveAdmin(httptest.NewRequest("GET", "/api/banners?rescan=1", nil), ""); rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("rescan with admin endpoints disabled returned %d, want %d", rec.Code, http.StatusServiceUnavailable)
+	}
+	useAdminToken(t)
+	for _, token := range []string{"", "wrong"} {
+		if rec := serveAdmin(httptest.NewRequest("GET", "/api/banners?rescan=1", nil), token); rec.Code != http.StatusUnauthorized {
+			t.Errorf("rescan with token %q returned %d, want %d", token, rec.Code, http.StatusUnauthorized)
+		}
+	}
+
+	details := func(target string) bannerInfo {
+		t.Helper()
+		rec := serveAdmin(httptest.NewRequest("GET", target, nil), testAdminToken)
+		var body bannersResponse
+		if err := json.NewDecoder(rec.Body).Decode(&body); err != nil || rec.Code != http.StatusOK || len(body.Details) != 1 {
+			t.Fatalf("GET %s returned %d, %+v, %v", target, rec.Code, body, err)
+		}
+		return body.Details[0]
+	}
+	if got := details("/api/banners?details=1"); got.Height != 8 {
+		t.Fatalf("height = %d, want 8", got.Height)
+	}
+	// A banner file changed on disk is parsed again on rescan
+	fsys["standard.txt"] = &fstest.MapFile{Data: boxes}
+	if got := details("/api/banners?details=1"); got.Height != 8 {
+		t.Errorf("height before rescan = %d, want the cached 8", got.Height)
+	}
+	if got := details("/api/banners?details=1&rescan=1"); got.Height != 3 || got.Size != int64(len(boxes)) {
+		t.Errorf("details after rescan = %+v, want height 3 and size %d", got, len(boxes))
+	}
+}
+
+func TestArtAPI(t *testing.T) {
+	rec := serveJSON("/api/ascii-art", `{"text":"Hi","banner":"standard"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("POST /api/ascii-art returned %d, want %d\n%s", rec.Code, http.StatusOK, rec.Body)
+	}
+	var body struct {
+		Art    string `json:"art"`
+		Result string `json:"result"`
+		Banner string `json:"banner"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatal(err)
+	}
+	want, err := renderBannerArt([]string{"standard"}, "Hi", asciiart.DefaultOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body.Art != want || body.Banner != "standard" {
+		t.Errorf("response = %+v, want art %q in standard", body, want)
+	}
+	if body.Result != body.Art {
+		t.Errorf("result = %q, want the same as art", body.Result)
+	}
+}
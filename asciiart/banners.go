@@ -0,0 +1,329 @@
+package asciiart
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// bannerExtensions lists the banner file formats in order of preference:
+// the project's own .txt layout and FIGlet .flf fonts
+var bannerExtensions = []string{".txt", ".flf"}
+
+// bannerFS is the directory banner files are read from, set with UseBannerFS
+var bannerFS fs.FS
+
+// bannerFSSource is the source of the banners in bannerFS that were not uploaded
+var bannerFSSource string
+
+// Sources of a banner, as reported in its BannerInfo.
+const (
+	SourceBuiltin  = "builtin"  // shipped with the server's assets
+	SourceUploaded = "uploaded" // uploaded at runtime
+	SourceCustom   = "custom"   // found in a separate banner directory
+)
+
+// UploadMarkerExt is the extension of the empty file, named after a banner,
+// that marks the banner as uploaded so it keeps that source across restarts
+const UploadMarkerExt = ".uploaded"
+
+// bannerCache holds parsed banner fonts and their details keyed by banner
+// name so each banner file is only read and parsed once.
+var (
+	bannerCache   = make(map[string]cachedBanner)
+	bannerCacheMu sync.RWMutex
+)
+
+// cachedBanner is a parsed banner font with the details worked out from it
+type cachedBanner struct {
+	font Font
+	info BannerInfo
+}
+
+// BannerInfo describes the layout of a banner font, for sizing art without
+// rendering it
+type BannerInfo struct {
+	Height     int    // rows in every glyph
+	SpaceWidth int    // columns of the space glyph
+	MinWidth   int    // columns of the narrowest glyph
+	MaxWidth   int    // columns of the widest glyph
+	Size       int64  // size of the banner file in bytes
+	Source     string // where the banner came from: SourceBuiltin, SourceUploaded or SourceCustom
+}
+
+// newCachedBanner works out the details of a font read from a file of size
+// bytes that came from source
+func newCachedBanner(font Font, size int64, source string) cachedBanner {
+	info := BannerInfo{Height: font.Height, SpaceWidth: blockWidth(font.Glyphs[' ']), Size: size, Source: source}
+	first := true
+	for _, art := range font.Glyphs {
+		width := blockWidth(art)
+		if first || width < info.MinWidth {
+			info.MinWidth = width
+		}
+		info.MaxWidth = max(info.MaxWidth, width)
+		first = false
+	}
+	return cachedBanner{font: font, info: info}
+}
+
+// UseBannerFS sets the directory banner files are read from and clears the
+// cache. Banners in it that are not marked as uploaded have the given source.
+func UseBannerFS(fsys fs.FS, source string) {
+	bannerCacheMu.Lock()
+	defer bannerCacheMu.Unlock()
+	bannerFS = fsys
+	bannerFSSource = source
+	bannerCache = make(map[string]cachedBanner)
+}
+
+// bannerSource returns the source of a banner in fsys, whose banners
+// otherwise come from source
+func bannerSource(fsys fs.FS, banner, source string) string {
+	if _, err := fs.Stat(fsys, banner+UploadMarkerExt); err == nil {
+		return SourceUploaded
+	}
+	return source
+}
+
+// Banners lists the names of the banner files in the banner directory
+func Banners() ([]string, error) {
+	bannerCacheMu.RLock()
+	fsys := bannerFS
+	bannerCacheMu.RUnlock()
+	return bannerNames(fsys)
+}
+
+// bannerNames lists the names of the banner files in fsys
+func bannerNames(fsys fs.FS) ([]string, error) {
+	if fsys == nil {
+		return nil, errors.New("no banner directory configured")
+	}
+
+	var banners []string
+	for _, ext := range bannerExtensions {
+		paths, err := fs.Glob(fsys, "*"+ext)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range paths {
+			banners = append(banners, strings.TrimSuffix(path.Base(p), ext))
+		}
+	}
+	slices.Sort(banners)
+	return slices.Compact(banners), nil
+}
+
+// BannerSet is every banner in the banner directory, parsed by ReadBanners
+// to replace the cache at once with UseBanners
+type BannerSet struct {
+	banners map[string]cachedBanner
+}
+
+// Names returns the sorted names of the banners in the set
+func (s BannerSet) Names() []string {
+	names := make([]string, 0, len(s.banners))
+	for name := range s.banners {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// ReadBanners reads and parses every banner file in the banner directory
+// without touching the cache. Banners that fail to parse are left out of
+// the set, and their errors are returned keyed by banner name.
+func ReadBanners() (BannerSet, map[string]error, error) {
+	bannerCacheMu.RLock()
+	fsys, source := bannerFS, bannerFSSource
+	bannerCacheMu.RUnlock()
+	names, err := bannerNames(fsys)
+	if err != nil {
+		return BannerSet{}, nil, err
+	}
+	set := BannerSet{banners: make(map[string]cachedBanner)}
+	problems := make(map[string]error)
+	for _, name := range names {
+		font, size, err := readBannerFile(fsys, name)
+		if err != nil {
+			problems[name] = fmt.Errorf("banner %q: %w", name, err)
+			continue
+		}
+		set.banners[name] = newCachedBanner(font, size, bannerSource(fsys, name, source))
+	}
+	return set, problems, nil
+}
+
+// UseBanners replaces the whole cache with the banners in set
+func UseBanners(set BannerSet) {
+	bannerCacheMu.Lock()
+	defer bannerCacheMu.Unlock()
+	bannerCache = make(map[string]cachedBanner, len(set.banners))
+	for name, cached := range set.banners {
+		bannerCache[name] = cached
+	}
+}
+
+// StoreBanner caches font, read from a file of size bytes, as the parsed
+// font for an uploaded banner, replacing any cached copy, for banners whose
+// file has just been written
+func StoreBanner(banner string, font Font, size int64) {
+	bannerCacheMu.Lock()
+	defer bannerCacheMu.Unlock()
+	bannerCache[banner] = newCachedBanner(font, size, SourceUploaded)
+}
+
+// LoadBanner returns the parsed font for a banner, reading it from the
+// banner directory and caching it the first time it is requested
+func LoadBanner(banner string) (Font, error) {
+	cached, err := loadCachedBanner(banner)
+	return cached.font, err
+}
+
+// BannerDetails returns the layout details of a banner, worked out when it
+// was first loaded
+func BannerDetails(banner string) (BannerInfo, error) {
+	cached, err := loadCachedBanner(banner)
+	return cached.info, err
+}
+
+// loadCachedBanner returns a banner from the cache, reading and caching it
+// the first time it is requested
+func loadCachedBanner(banner string) (cachedBanner, error) {
+	// Serve from the cache when the banner has already been parsed
+	bannerCacheMu.RLock()
+	cached, ok := bannerCache[banner]
+	bannerCacheMu.RUnlock()
+	if ok {
+		return cached, nil
+	}
+
+	// Fall back to reading the banner file, holding the write lock so
+	// concurrent first requests for the same banner only parse it once
+	bannerCacheMu.Lock()
+	defer bannerCacheMu.Unlock()
+	if cached, ok := bannerCache[banner]; ok {
+		return cached, nil
+	}
+	// Never build a path from a name that could escape the banner directory
+	if strings.ContainsAny(banner, `/\`) || strings.Contains(banner, "..") {
+		return cachedBanner{}, fmt.Errorf("invalid banner name %q", banner)
+	}
+	font, size, err := readBannerFile(bannerFS, banner)
+	if err != nil {
+		return cachedBanner{}, fmt.Errorf("banner %q: %w", banner, err)
+	}
+
+	cached = newCachedBanner(font, size, bannerSource(bannerFS, banner, bannerFSSource))
+	bannerCache[banner] = cached
+	return cached, nil
+}
+
+// readBannerFile opens the first file for a banner found in fsys and parses
+// it according to its format, also returning the size of the file
+func readBannerFile(fsys fs.FS, banner string) (Font, int64, error) {
+	if fsys == nil {
+		return Font{}, 0, errors.New("no banner directory configured")
+	}
+	for _, ext := range bannerExtensions {
+		content, err := fsys.Open(banner + ext)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return Font{}, 0, err
+		}
+		defer content.Close()
+		info, err := content.Stat()
+		if err != nil {
+			return Font{}, 0, err
+		}
+		var font Font
+		if ext == ".flf" {
+			font, err = LoadFIGletFont(content)
+		} else {
+			font, err = LoadFont(content)
+		}
+		return font, info.Size(), err
+	}
+	return Font{}, 0, fs.ErrNotExist
+}
+
+// Render generates ASCII art for the lines using the named banner and the default options
+func Render(banner string, lines []string) (string, error) {
+	return RenderWithOptions(banner, lines, DefaultOptions())
+}
+
+// RenderWithOptions generates ASCII art for the lines using the named banner
+func RenderWithOptions(banner string, lines []string, opts Options) (string, error) {
+	font, err := LoadBanner(banner)
+	if err != nil {
+		return "", err
+	}
+	return Generate(font, lines, opts)
+}
+
+// RenderPerLine generates ASCII art for the lines, rendering each line with
+// the banner at the same index in banners
+func RenderPerLine(banners []string, lines []string, opts Options) (string, error) {
+	fonts, err := loadBannerFonts(banners, len(lines))
+	if err != nil {
+		return "", err
+	}
+	return GeneratePerLine(fonts, lines, opts)
+}
+
+// RenderHighlighted generates ASCII art for the lines as HTML, coloring the
+// art for every occurrence of letters. A single banner applies to every line;
+// otherwise each line uses the banner at the same index in banners.
+func RenderHighlighted(banners []string, lines []string, opts Options, letters, color string) (template.HTML, error) {
+	fonts, err := loadBannerFonts(banners, len(lines))
+	if err != nil {
+		return "", err
+	}
+	return GenerateHighlighted(fonts, lines, opts, letters, color)
+}
+
+// RenderANSI generates ASCII art for the lines, coloring the art for every
+// occurrence of letters with ANSI escape sequences. Banners are chosen as
+// for RenderHighlighted.
+func RenderANSI(banners []string, lines []string, opts Options, letters, color string) (string, error) {
+	fonts, err := loadBannerFonts(banners, len(lines))
+	if err != nil {
+		return "", err
+	}
+	return GenerateANSI(fonts, lines, opts, letters, color)
+}
+
+// DecodeBanner recovers the text that the named banner renders as art
+func DecodeBanner(banner string, art string) (string, error) {
+	font, err := LoadBanner(banner)
+	if err != nil {
+		return "", err
+	}
+	return Decode(font, art)
+}
+
+// loadBannerFonts loads the font for each of n lines, repeating a single
+// banner for every line
+func loadBannerFonts(banners []string, n int) ([]Font, error) {
+	fonts := make([]Font, len(banners))
+	for i, banner := range banners {
+		font, err := LoadBanner(banner)
+		if err != nil {
+			return nil, err
+		}
+		fonts[i] = font
+	}
+	if len(fonts) == 1 {
+		for len(fonts) < n {
+			fonts = append(fonts, fonts[0])
+		}
+	}
+	return fonts, nil
+}
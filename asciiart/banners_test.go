@@ -0,0 +1,42 @@
+package asciiart
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+func TestBannerSource(t *testing.T) {
+	banner := &fstest.MapFile{Data: []byte(fixtureBanner(2, "\n"))}
+	fsys := fstest.MapFS{
+		"plain.txt":     banner,
+		"mine.txt":      banner,
+		"mine.uploaded": &fstest.MapFile{},
+	}
+	for _, source := range []string{SourceBuiltin, SourceCustom} {
+		UseBannerFS(fsys, source)
+		tests := map[string]string{"plain": source, "mine": SourceUploaded}
+		// Both loading one banner and reading them all record the source
+		for banner, want := range tests {
+			info, err := BannerDetails(banner)
+			if err != nil {
+				t.Fatalf("BannerDetails(%q) returned error: %v", banner, err)
+			}
+			if info.Source != want {
+				t.Errorf("BannerDetails(%q).Source = %q, want %q", banner, info.Source, want)
+			}
+		}
+		set, problems, err := ReadBanners()
+		if err != nil || len(problems) > 0 {
+			t.Fatalf("ReadBanners returned %v, %v", problems, err)
+		}
+		for banner, want := range tests {
+			if got := set.banners[banner].info.Source; got != want {
+				t.Errorf("ReadBanners source of %q = %q, want %q", banner, got, want)
+			}
+		}
+	}
+	StoreBanner("stored", fixtureFont(t), 1)
+	if info, _ := BannerDetails("stored"); info.Source != SourceUploaded {
+		t.Errorf("stored banner source = %q, want %q", info.Source, SourceUploaded)
+	}
+}
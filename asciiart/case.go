@@ -0,0 +1,47 @@
+package asciiart
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Case changes applied to the text before it is rendered.
+const (
+	CasePreserve = "preserve"
+	CaseUpper    = "upper"
+	CaseLower    = "lower"
+	CaseTitle    = "title" // capitalize the first letter of each word
+)
+
+// ChangeCase converts text to the named case. An empty mode preserves the text.
+func ChangeCase(text, mode string) (string, error) {
+	switch mode {
+	case "", CasePreserve:
+		return text, nil
+	case CaseUpper:
+		return strings.ToUpper(text), nil
+	case CaseLower:
+		return strings.ToLower(text), nil
+	case CaseTitle:
+		return titleCase(text), nil
+	}
+	return "", fmt.Errorf("Invalid case option %q: please use %s, %s, %s or %s.", mode, CasePreserve, CaseUpper, CaseLower, CaseTitle)
+}
+
+// titleCase capitalizes the first letter of each word and lowercases the
+// rest, where a word is anything between whitespace, so "don't" becomes
+// "Don't"
+func titleCase(text string) string {
+	var result strings.Builder
+	inWord := false
+	for _, char := range text {
+		if inWord {
+			result.WriteRune(unicode.ToLower(char))
+		} else {
+			result.WriteRune(unicode.ToUpper(char))
+		}
+		inWord = !unicode.IsSpace(char)
+	}
+	return result.String()
+}
@@ -0,0 +1,38 @@
+package asciiart
+
+import "testing"
+
+func TestChangeCase(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		mode string
+		want string
+	}{
+		{name: "preserve by default", text: "hELLo", mode: "", want: "hELLo"},
+		{name: "preserve", text: "hELLo", mode: CasePreserve, want: "hELLo"},
+		{name: "upper", text: "Hello 1", mode: CaseUpper, want: "HELLO 1"},
+		{name: "lower", text: "Hello 1", mode: CaseLower, want: "hello 1"},
+		{name: "title", text: "hELLO wORLD", mode: CaseTitle, want: "Hello World"},
+		{name: "title keeps spacing", text: "  a\tb\nc  ", mode: CaseTitle, want: "  A\tB\nC  "},
+		{name: "title with an apostrophe", text: "don't stop", mode: CaseTitle, want: "Don't Stop"},
+		{name: "title with punctuation", text: "hello-world (again)", mode: CaseTitle, want: "Hello-world (again)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ChangeCase(tt.text, tt.mode)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("ChangeCase(%q, %q) = %q, want %q", tt.text, tt.mode, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChangeCaseInvalid(t *testing.T) {
+	if _, err := ChangeCase("hi", "camel"); err == nil {
+		t.Error("ChangeCase accepted an unknown mode")
+	}
+}
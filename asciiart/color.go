@@ -0,0 +1,142 @@
+package asciiart
+
+import (
+	"fmt"
+	"html"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ansiReset restores the terminal's default colors
+const ansiReset = "\x1b[0m"
+
+// ansiColors maps color names to their ANSI foreground escape sequences
+var ansiColors = map[string]string{
+	"black":   "\x1b[30m",
+	"red":     "\x1b[31m",
+	"green":   "\x1b[32m",
+	"yellow":  "\x1b[33m",
+	"blue":    "\x1b[34m",
+	"magenta": "\x1b[35m",
+	"cyan":    "\x1b[36m",
+	"white":   "\x1b[37m",
+}
+
+// Colors lists the basic color names accepted for ANSI output
+var Colors = []string{"black", "red", "green", "yellow", "blue", "magenta", "cyan", "white"}
+
+// ColorRainbow colors each character with the next color of a rainbow
+const ColorRainbow = "rainbow"
+
+// rainbowColors is the palette ColorRainbow cycles through in HTML
+var rainbowColors = []string{"red", "orange", "yellow", "green", "blue", "purple"}
+
+// rainbowANSI is the rainbow palette as ANSI escape sequences, using 24-bit
+// color for the colors the basic terminal palette lacks
+var rainbowANSI = []string{
+	ansiColors["red"],
+	"\x1b[38;2;255;165;0m", // orange
+	ansiColors["yellow"],
+	ansiColors["green"],
+	ansiColors["blue"],
+	"\x1b[38;2;128;0;128m", // purple
+}
+
+// Output formats for colored art.
+const (
+	ColorModeHTML = "html" // colored spans for web pages
+	ColorModeANSI = "ansi" // escape sequences for terminals
+)
+
+// InvalidColorError reports a color that cannot be used for the output format
+type InvalidColorError struct {
+	Color string
+	ANSI  bool // whether the color was meant for ANSI escape sequences rather than HTML
+}
+
+func (e *InvalidColorError) Error() string {
+	if e.ANSI {
+		return fmt.Sprintf("Invalid color %q: please use one of %s, or a #rrggbb value.", e.Color, strings.Join(Colors, ", "))
+	}
+	return fmt.Sprintf("Invalid color %q: please use a CSS color name or a #rrggbb value.", e.Color)
+}
+
+// ansiColorCode returns the ANSI escape sequence for one of the basic color
+// names, or the 24-bit truecolor sequence for a #rrggbb value
+func ansiColorCode(color string) (string, bool) {
+	if code, ok := ansiColors[strings.ToLower(color)]; ok {
+		return code, true
+	}
+	if len(color) == 7 && color[0] == '#' {
+		rgb, err := strconv.ParseUint(color[1:], 16, 32)
+		if err == nil {
+			return fmt.Sprintf("\x1b[38;2;%d;%d;%dm", rgb>>16, rgb>>8&0xff, rgb&0xff), true
+		}
+	}
+	return "", false
+}
+
+// painter writes the columns of rendered art in an output format, marking
+// painted columns so they show in color
+type painter interface {
+	plain(text string) string
+	paint(text, color string) string
+}
+
+// htmlPainter escapes art for HTML and wraps painted columns in a span of a CSS color
+type htmlPainter struct{}
+
+func (htmlPainter) plain(text string) string {
+	return html.EscapeString(text)
+}
+
+func (htmlPainter) paint(text, color string) string {
+	return `<span style="color: ` + html.EscapeString(color) + `">` + html.EscapeString(text) + "</span>"
+}
+
+// ansiPainter wraps painted columns in an ANSI escape sequence and leaves the rest as is
+type ansiPainter struct{}
+
+func (ansiPainter) plain(text string) string {
+	return text
+}
+
+func (ansiPainter) paint(text, code string) string {
+	return code + text + ansiReset
+}
+
+// cssColorNames lists the named colors defined by CSS
+var cssColorNames = strings.Fields(`
+	aliceblue antiquewhite aqua aquamarine azure beige bisque black
+	blanchedalmond blue blueviolet brown burlywood cadetblue chartreuse
+	chocolate coral cornflowerblue cornsilk crimson cyan darkblue darkcyan
+	darkgoldenrod darkgray darkgreen darkgrey darkkhaki darkmagenta
+	darkolivegreen darkorange darkorchid darkred darksalmon darkseagreen
+	darkslateblue darkslategray darkslategrey darkturquoise darkviolet
+	deeppink deepskyblue dimgray dimgrey dodgerblue firebrick floralwhite
+	forestgreen fuchsia gainsboro ghostwhite gold goldenrod gray green
+	greenyellow grey honeydew hotpink indianred indigo ivory khaki lavender
+	lavenderblush lawngreen lemonchiffon lightblue lightcoral lightcyan
+	lightgoldenrodyellow lightgray lightgreen lightgrey lightpink
+	lightsalmon lightseagreen lightskyblue lightslategray lightslategrey
+	lightsteelblue lightyellow lime limegreen linen magenta maroon
+	mediumaquamarine mediumblue mediumorchid mediumpurple mediumseagreen
+	mediumslateblue mediumspringgreen mediumturquoise mediumvioletred
+	midnightblue mintcream mistyrose moccasin navajowhite navy oldlace olive
+	olivedrab orange orangered orchid palegoldenrod palegreen paleturquoise
+	palevioletred papayawhip peachpuff peru pink plum powderblue purple
+	rebeccapurple red rosybrown royalblue saddlebrown salmon sandybrown
+	seagreen seashell sienna silver skyblue slateblue slategray slategrey
+	snow springgreen steelblue tan teal thistle tomato turquoise violet
+	wheat white whitesmoke yellow yellowgreen
+`)
+
+// IsCSSColor reports whether color is a CSS color name or a #rrggbb value
+func IsCSSColor(color string) bool {
+	if len(color) == 7 && color[0] == '#' {
+		_, err := strconv.ParseUint(color[1:], 16, 32)
+		return err == nil
+	}
+	return slices.Contains(cssColorNames, strings.ToLower(color))
+}
@@ -0,0 +1,62 @@
+package asciiart
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestGenerateANSI(t *testing.T) {
+	fonts := []Font{fixtureFont(t)}
+	tests := []struct {
+		name    string
+		letters string
+		color   string
+		want    string
+	}{
+		{
+			name:  "whole art",
+			color: "red",
+			want:  "\x1b[31maabb\x1b[0m\n\x1b[31m____\x1b[0m\n",
+		},
+		{
+			name:  "color name in capitals",
+			color: "Blue",
+			want:  "\x1b[34maabb\x1b[0m\n\x1b[34m____\x1b[0m\n",
+		},
+		{
+			name:  "hex color",
+			color: "#ff8000",
+			want:  "\x1b[38;2;255;128;0maabb\x1b[0m\n\x1b[38;2;255;128;0m____\x1b[0m\n",
+		},
+		{
+			name:    "chosen letters",
+			letters: "b",
+			color:   "green",
+			want:    "aa\x1b[32mbb\x1b[0m\n__\x1b[32m__\x1b[0m\n",
+		},
+		{
+			name:  "rainbow",
+			color: ColorRainbow,
+			want:  "\x1b[31maa\x1b[0m\x1b[38;2;255;165;0mbb\x1b[0m\n\x1b[31m__\x1b[0m\x1b[38;2;255;165;0m__\x1b[0m\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GenerateANSI(fonts, []string{"ab"}, DefaultOptions(), tt.letters, tt.color)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("GenerateANSI = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenerateANSIRejectsCSSOnlyColors(t *testing.T) {
+	_, err := GenerateANSI([]Font{fixtureFont(t)}, []string{"ab"}, DefaultOptions(), "", "orange")
+	var invalid *InvalidColorError
+	if !errors.As(err, &invalid) || !invalid.ANSI {
+		t.Errorf("GenerateANSI with orange returned %v, want an ANSI InvalidColorError", err)
+	}
+}
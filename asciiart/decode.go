@@ -0,0 +1,140 @@
+package asciiart
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// DecodeError reports art that does not match the glyphs of the font
+type DecodeError struct {
+	Line   int // 1-based line of text, counting bands of font.Height rows
+	Column int // 1-based column of the art where no glyph matches
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("Cannot decode the art: no character matches line %d at column %d.", e.Line, e.Column)
+}
+
+// Decode recovers the text that font renders as art. The art is cut into
+// bands of font.Height rows, one per line of text, and each band is matched
+// against the glyphs from left to right. A band of blank rows decodes as an
+// empty line. Spaces after the last glyph of a line are ignored, so trailing
+// spaces in the original text are not recovered, and blank rows missing from
+// the end of the art are filled in.
+func Decode(font Font, art string) (string, error) {
+	if font.Height <= 0 {
+		return "", fmt.Errorf("font has no rows")
+	}
+	rows := SplitLines(strings.TrimSuffix(NormalizeLineEndings(art), "\n"))
+	for len(rows)%font.Height != 0 {
+		rows = append(rows, "")
+	}
+	chars := decodeOrder(font)
+	lines := make([]string, 0, len(rows)/font.Height)
+	for start := 0; start < len(rows); start += font.Height {
+		band := make([][]rune, font.Height)
+		for i := range band {
+			band[i] = []rune(rows[start+i])
+		}
+		d := decoder{font: font, chars: chars, band: band, failed: map[string]bool{}}
+		text, ok := d.match(make([]int, font.Height))
+		if !ok {
+			return "", &DecodeError{Line: len(lines) + 1, Column: d.furthest + 1}
+		}
+		lines = append(lines, text)
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// decodeOrder lists the characters of font in the order Decode tries them:
+// widest glyphs first, so a glyph is not mistaken for the start of a wider
+// one, then by character
+func decodeOrder(font Font) []rune {
+	var chars []rune
+	for char, art := range font.Glyphs {
+		if blockWidth(art) > 0 {
+			chars = append(chars, char)
+		}
+	}
+	slices.SortFunc(chars, func(a, b rune) int {
+		if wa, wb := blockWidth(font.Glyphs[a]), blockWidth(font.Glyphs[b]); wa != wb {
+			return wb - wa
+		}
+		return int(a - b)
+	})
+	return chars
+}
+
+// decoder matches one band of rows against the glyphs of a font
+type decoder struct {
+	font     Font
+	chars    []rune
+	band     [][]rune
+	failed   map[string]bool // offsets already known not to lead to a match
+	furthest int             // furthest column reached, for error reporting
+}
+
+// match returns the text whose art fills the band from the given column
+// offset of each row to the end, backtracking when a glyph leads nowhere
+func (d *decoder) match(offsets []int) (string, bool) {
+	d.furthest = max(d.furthest, slices.Min(offsets))
+	if d.blankFrom(offsets) {
+		return "", true
+	}
+	key := fmt.Sprint(offsets)
+	if d.failed[key] {
+		return "", false
+	}
+	for _, char := range d.chars {
+		art := d.font.Glyphs[char]
+		if !d.fits(art, offsets) {
+			continue
+		}
+		next := make([]int, len(offsets))
+		for i, row := range art {
+			next[i] = offsets[i] + len([]rune(row))
+		}
+		if rest, ok := d.match(next); ok {
+			return string(char) + rest, true
+		}
+	}
+	d.failed[key] = true
+	return "", false
+}
+
+// fits reports whether the rows of art appear in the band at offsets. Columns
+// past the end of a row count as spaces, since trailing spaces may be trimmed.
+func (d *decoder) fits(art []string, offsets []int) bool {
+	for i, row := range art {
+		column := offsets[i]
+		for _, char := range row {
+			if d.at(i, column) != char {
+				return false
+			}
+			column++
+		}
+	}
+	return true
+}
+
+// blankFrom reports whether every row of the band is blank from its offset on
+func (d *decoder) blankFrom(offsets []int) bool {
+	for i, row := range d.band {
+		for _, char := range row[min(offsets[i], len(row)):] {
+			if char != ' ' {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// at returns the character at a column of a row of the band, or a space
+// past its end
+func (d *decoder) at(row, column int) rune {
+	if column < len(d.band[row]) {
+		return d.band[row][column]
+	}
+	return ' '
+}
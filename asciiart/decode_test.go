@@ -0,0 +1,35 @@
+package asciiart
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestDecodeRoundTrip(t *testing.T) {
+	lines := []string{"Hello, World!", "", "  {x} = 42 - y?", "The quick brown fox"}
+	for _, banner := range []string{"standard", "shadow", "thinkertoy"} {
+		t.Run(banner, func(t *testing.T) {
+			font := loadFontFile(t, "../ART/"+banner+".txt")
+			art, err := Generate(font, lines, DefaultOptions())
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := Decode(font, art)
+			if err != nil {
+				t.Fatalf("Decode returned %v", err)
+			}
+			if want := strings.Join(lines, "\n"); got != want {
+				t.Errorf("Decode(Generate(%q)) = %q", want, got)
+			}
+		})
+	}
+}
+
+func TestDecodeUnknownArt(t *testing.T) {
+	_, err := Decode(fixtureFont(t), "aa€€\n____\n")
+	var decodeErr *DecodeError
+	if !errors.As(err, &decodeErr) || decodeErr.Line != 1 || decodeErr.Column != 3 {
+		t.Errorf("Decode returned %v, want a DecodeError at line 1, column 3", err)
+	}
+}
@@ -0,0 +1,46 @@
+package asciiart
+
+import (
+	"fmt"
+	"strings"
+)
+
+// InvalidEscapeError reports a backslash sequence that Unescape does not understand
+type InvalidEscapeError struct {
+	Sequence string
+	Position int // 1-based position of the backslash in the text
+}
+
+func (e *InvalidEscapeError) Error() string {
+	return fmt.Sprintf("Invalid escape sequence %q at position %d: only \\n (line break) and \\\\ (backslash) are supported.", e.Sequence, e.Position)
+}
+
+// Unescape turns the two-character sequence \n into a line break and \\
+// into a single backslash, as the original ascii-art command line tool does.
+// Any other backslash sequence, including a trailing backslash, is an error.
+func Unescape(text string) (string, error) {
+	if !strings.Contains(text, `\`) {
+		return text, nil
+	}
+	var result strings.Builder
+	chars := []rune(text)
+	for i := 0; i < len(chars); i++ {
+		if chars[i] != '\\' {
+			result.WriteRune(chars[i])
+			continue
+		}
+		if i+1 == len(chars) {
+			return "", &InvalidEscapeError{Sequence: `\`, Position: i + 1}
+		}
+		switch chars[i+1] {
+		case 'n':
+			result.WriteByte('\n')
+		case '\\':
+			result.WriteByte('\\')
+		default:
+			return "", &InvalidEscapeError{Sequence: string(chars[i : i+2]), Position: i + 1}
+		}
+		i++
+	}
+	return result.String(), nil
+}
@@ -0,0 +1,51 @@
+package asciiart
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestUnescape(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{name: "no escapes", text: "Hello", want: "Hello"},
+		{name: "line break", text: `Hello\nThere`, want: "Hello\nThere"},
+		{name: "trailing line break", text: `Hello\n`, want: "Hello\n"},
+		{name: "leading line break", text: `\nHello`, want: "\nHello"},
+		{name: "back-to-back line breaks", text: `a\n\nb`, want: "a\n\nb"},
+		{name: "only line breaks", text: `\n\n`, want: "\n\n"},
+		{name: "escaped backslash", text: `a\\nb`, want: `a\nb`},
+		{name: "escaped backslash then line break", text: `a\\\nb`, want: "a\\\nb"},
+		{name: "trailing escaped backslash", text: `a\\`, want: `a\`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Unescape(tt.text)
+			if err != nil || got != tt.want {
+				t.Errorf("Unescape(%q) = %q, %v, want %q", tt.text, got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnescapeInvalid(t *testing.T) {
+	tests := []struct {
+		text string
+		want InvalidEscapeError
+	}{
+		{text: `abc\`, want: InvalidEscapeError{Sequence: `\`, Position: 4}},
+		{text: `a\tb`, want: InvalidEscapeError{Sequence: `\t`, Position: 2}},
+		{text: `\n\x`, want: InvalidEscapeError{Sequence: `\x`, Position: 3}},
+		{text: `é\q`, want: InvalidEscapeError{Sequence: `\q`, Position: 2}},
+	}
+	for _, tt := range tests {
+		_, err := Unescape(tt.text)
+		var invalid *InvalidEscapeError
+		if !errors.As(err, &invalid) || *invalid != tt.want {
+			t.Errorf("Unescape(%q) returned %v, want %+v", tt.text, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package asciiart
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// figletSignature starts the header line of every FIGlet font file
+const figletSignature = "flf2a"
+
+// LoadFIGletFont reads a FIGlet .flf font. Only the printable ASCII
+// characters are read; any further characters declared by the font are
+// ignored.
+func LoadFIGletFont(r io.Reader) (Font, error) {
+	scanner := bufio.NewScanner(r)
+	if !scanner.Scan() {
+		return Font{}, fmt.Errorf("FIGlet font file is empty")
+	}
+
+	// The header is "flf2a<hardblank> height baseline max_length old_layout comment_lines ..."
+	header := strings.TrimSuffix(scanner.Text(), "\r")
+	if !strings.HasPrefix(header, figletSignature) || len(header) <= len(figletSignature) {
+		return Font{}, fmt.Errorf("FIGlet font file has no %s signature", figletSignature)
+	}
+	hardblank := header[len(figletSignature)]
+	fields := strings.Fields(header[len(figletSignature)+1:])
+	if len(fields) < 5 {
+		return Font{}, fmt.Errorf("FIGlet font header is incomplete")
+	}
+	height, err := strconv.Atoi(fields[0])
+	if err != nil || height < 1 {
+		return Font{}, fmt.Errorf("FIGlet font header has an invalid height %q", fields[0])
+	}
+	commentLines, err := strconv.Atoi(fields[4])
+	if err != nil || commentLines < 0 {
+		return Font{}, fmt.Errorf("FIGlet font header has an invalid comment line count %q", fields[4])
+	}
+
+	// Skip the comment lines that follow the header.
+	for i := 0; i < commentLines; i++ {
+		if !scanner.Scan() {
+			return Font{}, fmt.Errorf("FIGlet font file is truncated in its comments")
+		}
+	}
+
+	font := Font{Height: height, Glyphs: make(map[rune][]string)}
+	for i := 32; i <= 126; i++ { // For all printable ASCII characters
+		asciiArt := make([]string, height)
+		for j := range asciiArt {
+			if !scanner.Scan() {
+				if err := scanner.Err(); err != nil {
+					return Font{}, fmt.Errorf("error reading FIGlet font file: %w", err)
+				}
+				return Font{}, fmt.Errorf("FIGlet font file is truncated: no glyph for %q", rune(i))
+			}
+			asciiArt[j] = figletRow(strings.TrimSuffix(scanner.Text(), "\r"), hardblank, j == height-1)
+		}
+		font.Glyphs[rune(i)] = asciiArt
+	}
+
+	return font, nil
+}
+
+// figletRow strips the end mark from a FIGlet glyph row and turns
+// hardblanks into spaces. The end mark is the row's last character, and the
+// glyph's final row carries it twice; only those copies are stripped, so art
+// ending in the same character is kept.
+func figletRow(row string, hardblank byte, last bool) string {
+	if row != "" {
+		endmark := row[len(row)-1:]
+		row = row[:len(row)-1]
+		if last {
+			row = strings.TrimSuffix(row, endmark)
+		}
+	}
+	return strings.ReplaceAll(row, string(hardblank), " ")
+}
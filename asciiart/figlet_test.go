@@ -0,0 +1,86 @@
+package asciiart
+
+import (
+	"strings"
+	"testing"
+)
+
+// figletFixture returns a FIGlet font of two rows with '$' hardblanks and
+// '@' end marks. Each glyph is the character twice over underscores, and
+// the space glyph is made of hardblanks.
+func figletFixture() string {
+	var font strings.Builder
+	font.WriteString("flf2a$ 2 1 8 -1 1\nA test font\n")
+	for char := ' '; char <= '~'; char++ {
+		top, bottom := string(char)+string(char), "__"
+		if char == ' ' {
+			top, bottom = "$$", "$$"
+		}
+		font.WriteString(top + "@\n" + bottom + "@@\n")
+	}
+	return font.String()
+}
+
+func TestLoadFIGletFont(t *testing.T) {
+	font, err := LoadFIGletFont(strings.NewReader(figletFixture()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		char rune
+		want []string
+	}{
+		{char: 'a', want: []string{"aa", "__"}},
+		// Glyphs ending in the end mark character keep it
+		{char: '@', want: []string{"@@", "__"}},
+		{char: '_', want: []string{"__", "__"}},
+		{char: ' ', want: []string{"  ", "  "}},
+	}
+	for _, tt := range tests {
+		got := font.Glyphs[tt.char]
+		if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+			t.Errorf("glyph for %q = %q, want %q", tt.char, got, tt.want)
+		}
+	}
+	art, err := Generate(font, []string{"a @"}, DefaultOptions())
+	if want := "aa  @@\n__  __\n"; err != nil || art != want {
+		t.Errorf("Generate = %q, %v, want %q", art, err, want)
+	}
+}
+
+func TestLoadFIGletFontHardblanks(t *testing.T) {
+	// A hardblank inside a glyph is a space that is part of the art
+	fixture := strings.Replace(figletFixture(), "bb@\n", "b$b@\n", 1)
+	font, err := LoadFIGletFont(strings.NewReader(fixture))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := font.Glyphs['b'][0]; got != "b b" {
+		t.Errorf("top row of 'b' = %q, want %q", got, "b b")
+	}
+}
+
+func TestLoadFIGletFontErrors(t *testing.T) {
+	fixture := figletFixture()
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{name: "empty", content: "", want: "FIGlet font file is empty"},
+		{name: "no signature", content: "flf2\n", want: "FIGlet font file has no flf2a signature"},
+		{name: "incomplete header", content: "flf2a$ 2 1 8\n", want: "FIGlet font header is incomplete"},
+		{name: "invalid height", content: "flf2a$ x 1 8 -1 0\n", want: `FIGlet font header has an invalid height "x"`},
+		{name: "invalid comment count", content: "flf2a$ 2 1 8 -1 -3\n", want: `FIGlet font header has an invalid comment line count "-3"`},
+		{name: "missing comments", content: "flf2a$ 2 1 8 -1 5\nonly one\n", want: "FIGlet font file is truncated in its comments"},
+		{name: "truncated", content: fixture[:strings.Index(fixture, "**@")], want: "FIGlet font file is truncated: no glyph for '*'"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadFIGletFont(strings.NewReader(tt.content))
+			if err == nil || err.Error() != tt.want {
+				t.Errorf("LoadFIGletFont returned %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
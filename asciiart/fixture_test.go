@@ -0,0 +1,70 @@
+package asciiart
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// update rewrites the golden files with the current output
+var update = flag.Bool("update", false, "rewrite the golden files in testdata/golden")
+
+// fixtureBanner returns a banner file in the .txt layout with glyphs of
+// height rows and two columns: the character twice on the top row and
+// underscores below. The space glyph is blank.
+func fixtureBanner(height int, lineEnding string) string {
+	var banner strings.Builder
+	for char := ' '; char <= '~'; char++ {
+		banner.WriteString(lineEnding)
+		for row := range height {
+			switch {
+			case char == ' ':
+				banner.WriteString("  ")
+			case row == 0:
+				banner.WriteString(string(char) + string(char))
+			default:
+				banner.WriteString("__")
+			}
+			banner.WriteString(lineEnding)
+		}
+	}
+	return banner.String()
+}
+
+// fixtureFont loads a two-row fixture banner
+func fixtureFont(t *testing.T) Font {
+	t.Helper()
+	font, err := LoadFont(strings.NewReader(fixtureBanner(2, "\n")))
+	if err != nil {
+		t.Fatalf("loading fixture banner: %v", err)
+	}
+	return font
+}
+
+// standardFont loads the standard banner shipped with the server
+func standardFont(t *testing.T) Font {
+	t.Helper()
+	return loadFontFile(t, "../ART/standard.txt")
+}
+
+// checkGolden compares got with the golden file testdata/golden/name,
+// rewriting the file instead when the tests run with -update
+func checkGolden(t *testing.T, name, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", "golden", name)
+	if *update {
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading golden file: %v (run go test -update to create it)", err)
+	}
+	if got != string(want) {
+		t.Errorf("output does not match %s:\n%s\nwant\n%s", path, got, want)
+	}
+}
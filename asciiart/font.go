@@ -0,0 +1,185 @@
+// Package asciiart turns text into ASCII art using banner fonts.
+package asciiart
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// Font holds the art for every printable ASCII character of a banner
+type Font struct {
+	// Height is the number of rows in every character's art
+	Height int
+	// Glyphs maps each character to its rows of art
+	Glyphs map[rune][]string
+}
+
+// Line ending styles reported by CheckFont.
+const (
+	LineEndingLF    = "lf"
+	LineEndingCRLF  = "crlf"
+	LineEndingMixed = "mixed"
+	LineEndingNone  = "none" // a single line with no line break
+)
+
+// FontReport describes the structure of a banner file in the .txt layout
+// and any problems that stop it from loading
+type FontReport struct {
+	Valid       bool           `json:"valid"`
+	Height      int            `json:"height"`      // rows in the first glyph, which every glyph must match
+	Blocks      int            `json:"blocks"`      // blocks of art separated by blank lines
+	Missing     []string       `json:"missing"`     // characters with no block of art
+	Malformed   []GlyphProblem `json:"malformed"`   // characters whose art has the wrong number of rows
+	Ragged      []string       `json:"ragged"`      // characters whose rows differ in width; allowed, but the art will not line up
+	LineEndings string         `json:"lineEndings"` // lf, crlf, mixed or none
+}
+
+// GlyphProblem describes a character whose art has the wrong number of rows
+type GlyphProblem struct {
+	Char  string `json:"char"`
+	Lines int    `json:"lines"`
+}
+
+// Err returns the first problem that stops the font from loading, or nil
+func (report FontReport) Err() error {
+	switch {
+	case report.Blocks == 0:
+		return fmt.Errorf("banner font file is empty")
+	case len(report.Malformed) > 0:
+		problem := report.Malformed[0]
+		char, _ := utf8.DecodeRuneInString(problem.Char)
+		return fmt.Errorf("glyph for %q has %d lines, expected %d", char, problem.Lines, report.Height)
+	case len(report.Missing) > 0:
+		char, _ := utf8.DecodeRuneInString(report.Missing[0])
+		return fmt.Errorf("banner font file is truncated: no glyph for %q", char)
+	}
+	return nil
+}
+
+// LoadFont reads a banner in the .txt layout: the art for each printable
+// ASCII character as a block of lines, with blocks separated by blank
+// lines. The height is taken from the first block and every block must
+// match it.
+func LoadFont(r io.Reader) (Font, error) {
+	blocks, report, err := readFont(r)
+	if err != nil {
+		return Font{}, err
+	}
+	if err := report.Err(); err != nil {
+		return Font{}, err
+	}
+	font := Font{Height: report.Height, Glyphs: make(map[rune][]string)}
+	for i := 32; i <= 126; i++ { // For all printable ASCII characters
+		font.Glyphs[rune(i)] = blocks[i-32]
+	}
+	return font, nil
+}
+
+// CheckFont reads a banner in the .txt layout, as LoadFont does, and reports
+// on its structure. The error is only for failing to read r; problems with
+// the file itself are in the report.
+func CheckFont(r io.Reader) (FontReport, error) {
+	_, report, err := readFont(r)
+	return report, err
+}
+
+// readFont splits a banner file into its blocks of character art and
+// checks them against the printable ASCII characters
+func readFont(r io.Reader) ([][]string, FontReport, error) {
+	// Read every line of the banner file, dropping the \r of CRLF line
+	// endings so Windows-saved banners parse the same as LF ones.
+	var lines []string
+	crlf, lf := 0, 0
+	scanner := bufio.NewScanner(r)
+	scanner.Split(scanLinesKeepCR)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasSuffix(line, "\r\n") {
+			crlf++
+		} else if strings.HasSuffix(line, "\n") {
+			lf++
+		}
+		lines = append(lines, strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r"))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, FontReport{}, fmt.Errorf("error reading banner font file: %w", err)
+	}
+
+	// Split the file into blocks of character art separated by blank lines.
+	var blocks [][]string
+	for start := 0; start < len(lines); {
+		if lines[start] == "" {
+			start++
+			continue
+		}
+		end := start
+		for end < len(lines) && lines[end] != "" {
+			end++
+		}
+		blocks = append(blocks, lines[start:end])
+		start = end
+	}
+
+	report := FontReport{Blocks: len(blocks), Missing: []string{}, Malformed: []GlyphProblem{}, Ragged: []string{}, LineEndings: lineEndingStyle(crlf, lf)}
+	if len(blocks) == 0 {
+		return nil, report, nil
+	}
+	// The first character's block sets the height every other character must match.
+	report.Height = len(blocks[0])
+	for i := 32; i <= 126; i++ { // For all printable ASCII characters
+		char := string(rune(i))
+		if i-32 >= len(blocks) {
+			report.Missing = append(report.Missing, char)
+			continue
+		}
+		block := blocks[i-32]
+		if len(block) != report.Height {
+			report.Malformed = append(report.Malformed, GlyphProblem{Char: char, Lines: len(block)})
+		}
+		if blockWidth(block) != minRowWidth(block) {
+			report.Ragged = append(report.Ragged, char)
+		}
+	}
+	report.Valid = report.Err() == nil
+	return blocks, report, nil
+}
+
+// scanLinesKeepCR is a bufio.SplitFunc like bufio.ScanLines that keeps the
+// line ending, so the style of line endings can be told apart
+func scanLinesKeepCR(data []byte, atEOF bool) (int, []byte, error) {
+	if i := bytes.IndexByte(data, '\n'); i >= 0 {
+		return i + 1, data[:i+1], nil
+	}
+	if atEOF && len(data) > 0 {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
+
+// lineEndingStyle names the line endings of a file from its counts of CRLF and LF line breaks
+func lineEndingStyle(crlf, lf int) string {
+	switch {
+	case crlf > 0 && lf > 0:
+		return LineEndingMixed
+	case crlf > 0:
+		return LineEndingCRLF
+	case lf > 0:
+		return LineEndingLF
+	}
+	return LineEndingNone
+}
+
+// minRowWidth returns the width in columns of the narrowest row in a block
+func minRowWidth(rows []string) int {
+	width := -1
+	for _, row := range rows {
+		if n := utf8.RuneCountInString(row); width < 0 || n < width {
+			width = n
+		}
+	}
+	return max(width, 0)
+}
@@ -0,0 +1,154 @@
+package asciiart
+
+import (
+	"os"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// loadFontFile loads a banner file in the .txt layout
+func loadFontFile(t *testing.T, path string) Font {
+	t.Helper()
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	font, err := LoadFont(file)
+	if err != nil {
+		t.Fatalf("loading %s: %v", path, err)
+	}
+	return font
+}
+
+func TestLoadFontCRLF(t *testing.T) {
+	lf := loadFontFile(t, "../ART/standard.txt")
+	crlf := loadFontFile(t, "testdata/standard-crlf.txt")
+	lines := []string{"Hello, World!", "{ascii} ~ 42"}
+	want, err := Generate(lf, lines, DefaultOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := Generate(crlf, lines, DefaultOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != want {
+		t.Errorf("art from the CRLF banner =\n%q\nwant the LF banner's\n%q", got, want)
+	}
+
+	report, err := CheckFont(strings.NewReader(fixtureBanner(2, "\r\n")))
+	if err != nil || !report.Valid || report.LineEndings != LineEndingCRLF {
+		t.Errorf("CheckFont of a CRLF banner = %+v, %v, want a valid banner with crlf line endings", report, err)
+	}
+}
+
+func TestLoadFontHeight(t *testing.T) {
+	font := loadFontFile(t, "testdata/boxes.txt")
+	if font.Height != 3 {
+		t.Fatalf("height = %d, want 3", font.Height)
+	}
+	got, err := Generate(font, []string{"Hi 5", "", "?"}, DefaultOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "" +
+		"+-++-+   +-+\n" +
+		"|H||i|   |5|\n" +
+		"+-++-+   +-+\n" +
+		"\n\n\n" +
+		"+-+\n" +
+		"|?|\n" +
+		"+-+\n"
+	if got != want {
+		t.Errorf("art in a 3-line banner =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestLoadFontMixedHeights(t *testing.T) {
+	content, err := os.ReadFile("testdata/boxes.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Drop the middle row of 'M'
+	broken := strings.Replace(string(content), "|M|\n", "", 1)
+	_, err = LoadFont(strings.NewReader(broken))
+	if want := "glyph for 'M' has 2 lines, expected 3"; err == nil || err.Error() != want {
+		t.Errorf("LoadFont of mixed heights returned %v, want %q", err, want)
+	}
+}
+
+func TestCheckFontBrokenFixtures(t *testing.T) {
+	banner := fixtureBanner(2, "\n")
+	tests := []struct {
+		name        string
+		content     string
+		wantErr     string
+		missing     int
+		malformed   []GlyphProblem
+		ragged      []string
+		lineEndings string
+	}{
+		{name: "valid", content: banner, lineEndings: LineEndingLF},
+		{name: "empty", content: "", wantErr: "banner font file is empty", lineEndings: LineEndingNone},
+		{name: "only blank lines", content: "\n\n\n", wantErr: "banner font file is empty", lineEndings: LineEndingLF},
+		{
+			name:        "truncated",
+			content:     banner[:strings.Index(banner, "\n**\n")],
+			wantErr:     "banner font file is truncated: no glyph for '*'",
+			missing:     '~' - '*' + 1,
+			lineEndings: LineEndingLF,
+		},
+		{
+			name:        "short glyph",
+			content:     strings.Replace(banner, "\nMM\n__\n", "\nMM\n", 1),
+			wantErr:     "glyph for 'M' has 1 lines, expected 2",
+			malformed:   []GlyphProblem{{Char: "M", Lines: 1}},
+			lineEndings: LineEndingLF,
+		},
+		{
+			name:        "ragged glyph",
+			content:     strings.Replace(banner, "\nMM\n__\n", "\nMM\n___\n", 1),
+			ragged:      []string{"M"},
+			lineEndings: LineEndingLF,
+		},
+		{
+			name:        "mixed line endings",
+			content:     strings.Replace(banner, "\nMM\n", "\nMM\r\n", 1),
+			lineEndings: LineEndingMixed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, err := CheckFont(strings.NewReader(tt.content))
+			if err != nil {
+				t.Fatalf("CheckFont returned %v", err)
+			}
+			if report.Valid != (tt.wantErr == "") {
+				t.Errorf("valid = %v, want %v", report.Valid, tt.wantErr == "")
+			}
+			if len(report.Missing) != tt.missing {
+				t.Errorf("missing = %q, want %d characters", report.Missing, tt.missing)
+			}
+			if !slices.Equal(report.Malformed, tt.malformed) {
+				t.Errorf("malformed = %+v, want %+v", report.Malformed, tt.malformed)
+			}
+			if !slices.Equal(report.Ragged, tt.ragged) {
+				t.Errorf("ragged = %q, want %q", report.Ragged, tt.ragged)
+			}
+			if report.LineEndings != tt.lineEndings {
+				t.Errorf("line endings = %q, want %q", report.LineEndings, tt.lineEndings)
+			}
+
+			// LoadFont fails with the report's first problem
+			_, err = LoadFont(strings.NewReader(tt.content))
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("LoadFont returned %v, want no error", err)
+			}
+			if tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr) {
+				t.Errorf("LoadFont returned %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
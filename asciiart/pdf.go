@@ -0,0 +1,131 @@
+package asciiart
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Page orientations for PDF export.
+const (
+	OrientationPortrait  = "portrait"
+	OrientationLandscape = "landscape"
+)
+
+// Bounds for PDFOptions.FontSize.
+const (
+	MinPDFFontSize = 4
+	MaxPDFFontSize = 36
+)
+
+// PDF page layout in points: A4 paper with a half-inch margin
+const (
+	pdfPageWidth  = 595
+	pdfPageHeight = 842
+	pdfMargin     = 36
+)
+
+// PDFOptions controls how art is laid out in a PDF document
+type PDFOptions struct {
+	FontSize    int    // font size in points
+	Orientation string // OrientationPortrait or OrientationLandscape
+}
+
+// DefaultPDFOptions returns the PDF options used when a request sets none
+func DefaultPDFOptions() PDFOptions {
+	return PDFOptions{FontSize: 10, Orientation: OrientationPortrait}
+}
+
+// Validate fills in defaults for unset options and rejects invalid values
+func (opts *PDFOptions) Validate() error {
+	defaults := DefaultPDFOptions()
+	if opts.FontSize == 0 {
+		opts.FontSize = defaults.FontSize
+	}
+	if opts.FontSize < MinPDFFontSize || opts.FontSize > MaxPDFFontSize {
+		return fmt.Errorf("Invalid font size %d: please use %d to %d points.", opts.FontSize, MinPDFFontSize, MaxPDFFontSize)
+	}
+	switch opts.Orientation {
+	case "":
+		opts.Orientation = defaults.Orientation
+	case OrientationPortrait, OrientationLandscape:
+	default:
+		return fmt.Errorf("Invalid orientation %q: please use %s or %s.", opts.Orientation, OrientationPortrait, OrientationLandscape)
+	}
+	return nil
+}
+
+// PDF lays rendered art out in the Courier font on A4 pages and writes it
+// to w as a PDF document, starting a new page whenever the rows no longer
+// fit on the current one
+func PDF(w io.Writer, art string, opts PDFOptions) error {
+	width, height := pdfPageWidth, pdfPageHeight
+	if opts.Orientation == OrientationLandscape {
+		width, height = height, width
+	}
+	leading := float64(opts.FontSize) * 1.2
+	perPage := max(int(float64(height-2*pdfMargin)/leading), 1)
+	rows := strings.Split(strings.TrimSuffix(art, "\n"), "\n")
+	var pages [][]string
+	for len(rows) > perPage {
+		pages = append(pages, rows[:perPage])
+		rows = rows[perPage:]
+	}
+	pages = append(pages, rows)
+
+	// Objects 1 to 3 are the catalog, the page tree and the font; each page
+	// then takes two objects, the page and its content stream
+	var doc bytes.Buffer
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, doc.Len())
+		fmt.Fprintf(&doc, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+	doc.WriteString("%PDF-1.4\n")
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 4+2*i)
+	}
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Courier >>")
+	for i, page := range pages {
+		var content strings.Builder
+		fmt.Fprintf(&content, "BT\n/F1 %d Tf\n%g TL\n%d %g Td\n", opts.FontSize, leading, pdfMargin, float64(height-pdfMargin)-float64(opts.FontSize))
+		for _, row := range page {
+			fmt.Fprintf(&content, "(%s) Tj T*\n", escapePDFString(row))
+		}
+		content.WriteString("ET")
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>", width, height, 5+2*i))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", content.Len(), content.String()))
+	}
+
+	// The cross-reference table lists the byte offset of every object
+	xref := doc.Len()
+	fmt.Fprintf(&doc, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&doc, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&doc, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+	_, err := doc.WriteTo(w)
+	return err
+}
+
+// escapePDFString escapes text for a PDF string literal. Courier only
+// covers ASCII here, so other characters are replaced with '?'.
+func escapePDFString(text string) string {
+	var escaped strings.Builder
+	for _, char := range text {
+		switch {
+		case char == '(' || char == ')' || char == '\\':
+			escaped.WriteByte('\\')
+			escaped.WriteRune(char)
+		case char < ' ' || char > '~':
+			escaped.WriteByte('?')
+		default:
+			escaped.WriteRune(char)
+		}
+	}
+	return escaped.String()
+}
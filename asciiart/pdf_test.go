@@ -0,0 +1,53 @@
+package asciiart
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestPDFPages(t *testing.T) {
+	// At 10 points with 12 point leading, a portrait page holds 64 rows and
+	// a landscape page 43
+	tests := []struct {
+		name        string
+		rows        int
+		orientation string
+		pages       int
+	}{
+		{name: "one row", rows: 1, orientation: OrientationPortrait, pages: 1},
+		{name: "full page", rows: 64, orientation: OrientationPortrait, pages: 1},
+		{name: "one row over", rows: 65, orientation: OrientationPortrait, pages: 2},
+		{name: "landscape", rows: 65, orientation: OrientationLandscape, pages: 2},
+		{name: "landscape over twice", rows: 87, orientation: OrientationLandscape, pages: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := DefaultPDFOptions()
+			opts.Orientation = tt.orientation
+			var buf bytes.Buffer
+			if err := PDF(&buf, strings.Repeat("row\n", tt.rows), opts); err != nil {
+				t.Fatal(err)
+			}
+			doc := buf.String()
+			if !strings.HasPrefix(doc, "%PDF-") {
+				t.Errorf("document starts with %q, want a %%PDF header", doc[:min(len(doc), 8)])
+			}
+			if !strings.HasSuffix(doc, "%%EOF\n") {
+				t.Error("document does not end with an EOF marker")
+			}
+			if got := strings.Count(doc, "/Type /Page "); got != tt.pages {
+				t.Errorf("document has %d page objects, want %d", got, tt.pages)
+			}
+			if got := strings.Count(doc, "(row) Tj"); got != tt.rows {
+				t.Errorf("document shows %d rows, want %d", got, tt.rows)
+			}
+		})
+	}
+}
+
+func TestEscapePDFString(t *testing.T) {
+	if got, want := escapePDFString(`(a)\b é`), `\(a\)\\b ?`; got != want {
+		t.Errorf("escapePDFString = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,124 @@
+package asciiart
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/image/colornames"
+	"golang.org/x/image/draw"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+// Bounds for PNGOptions.Scale.
+const (
+	MinPNGScale = 1
+	MaxPNGScale = 8
+)
+
+// MaxPNGSide is the largest width or height in pixels of a PNG image
+const MaxPNGSide = 4096
+
+// pngFace is the bitmap font PNG images are drawn with; each character
+// fills a cell of pngCellWidth by pngCellHeight pixels
+var pngFace = basicfont.Face7x13
+
+const (
+	pngCellWidth  = 7
+	pngCellHeight = 13
+)
+
+// PNGOptions controls how art is drawn as a PNG image
+type PNGOptions struct {
+	Scale      int    // pixels per font pixel
+	Foreground string // CSS color of the art
+	Background string // CSS color behind the art
+}
+
+// DefaultPNGOptions returns the PNG options used when a request sets none
+func DefaultPNGOptions() PNGOptions {
+	return PNGOptions{Scale: 1, Foreground: "black", Background: "white"}
+}
+
+// Validate fills in defaults for unset options and rejects invalid values
+func (opts *PNGOptions) Validate() error {
+	defaults := DefaultPNGOptions()
+	if opts.Scale == 0 {
+		opts.Scale = defaults.Scale
+	}
+	if opts.Scale < MinPNGScale || opts.Scale > MaxPNGScale {
+		return fmt.Errorf("Invalid scale %d: please use %d to %d.", opts.Scale, MinPNGScale, MaxPNGScale)
+	}
+	if opts.Foreground == "" {
+		opts.Foreground = defaults.Foreground
+	}
+	if opts.Background == "" {
+		opts.Background = defaults.Background
+	}
+	for _, name := range []string{opts.Foreground, opts.Background} {
+		if _, ok := parseColor(name); !ok {
+			return &InvalidColorError{Color: name}
+		}
+	}
+	return nil
+}
+
+// ImageTooLargeError reports art too big to draw as an image
+type ImageTooLargeError struct {
+	Width, Height int // size of the image in pixels
+}
+
+func (e *ImageTooLargeError) Error() string {
+	return fmt.Sprintf("Image too large: the art would be %dx%d pixels, but images are limited to %d pixels on each side. Please shorten the text or lower the scale.", e.Width, e.Height, MaxPNGSide)
+}
+
+// PNG draws rendered art with a monospace bitmap font and writes it to w as
+// a PNG image of columns*7 by rows*13 pixels, times the scale
+func PNG(w io.Writer, art string, opts PNGOptions) error {
+	rows := strings.Split(strings.TrimSuffix(art, "\n"), "\n")
+	columns := 0
+	for _, row := range rows {
+		columns = max(columns, utf8.RuneCountInString(row))
+	}
+	width, height := columns*pngCellWidth, len(rows)*pngCellHeight
+	if width*opts.Scale > MaxPNGSide || height*opts.Scale > MaxPNGSide {
+		return &ImageTooLargeError{Width: width * opts.Scale, Height: height * opts.Scale}
+	}
+	fg, _ := parseColor(opts.Foreground)
+	bg, _ := parseColor(opts.Background)
+
+	// Draw the art at the font's own size, then scale it up without smoothing
+	img := image.NewRGBA(image.Rect(0, 0, max(width, 1), max(height, 1)))
+	draw.Draw(img, img.Bounds(), image.NewUniform(bg), image.Point{}, draw.Src)
+	drawer := font.Drawer{Dst: img, Src: image.NewUniform(fg), Face: pngFace}
+	for i, row := range rows {
+		drawer.Dot = fixed.P(0, i*pngCellHeight+pngFace.Ascent)
+		drawer.DrawString(row)
+	}
+	if opts.Scale > 1 {
+		scaled := image.NewRGBA(image.Rect(0, 0, img.Bounds().Dx()*opts.Scale, img.Bounds().Dy()*opts.Scale))
+		draw.NearestNeighbor.Scale(scaled, scaled.Bounds(), img, img.Bounds(), draw.Src, nil)
+		img = scaled
+	}
+	return png.Encode(w, img)
+}
+
+// parseColor converts a CSS color name or #rrggbb value to a color
+func parseColor(name string) (color.RGBA, bool) {
+	if len(name) == 7 && name[0] == '#' {
+		rgb, err := strconv.ParseUint(name[1:], 16, 32)
+		if err != nil {
+			return color.RGBA{}, false
+		}
+		return color.RGBA{R: uint8(rgb >> 16), G: uint8(rgb >> 8), B: uint8(rgb), A: 0xff}, true
+	}
+	c, ok := colornames.Map[strings.ToLower(name)]
+	return c, ok
+}
@@ -0,0 +1,51 @@
+package asciiart
+
+import (
+	"bytes"
+	"errors"
+	"image/png"
+	"testing"
+)
+
+func TestPNGDimensions(t *testing.T) {
+	tests := []struct {
+		name          string
+		art           string
+		scale         int
+		width, height int
+	}{
+		{name: "one row", art: "abc\n", scale: 1, width: 3 * 7, height: 13},
+		{name: "widest row sets the width", art: "ab\nabcd\na\n", scale: 1, width: 4 * 7, height: 3 * 13},
+		{name: "scaled", art: "ab\nab\n", scale: 3, width: 2 * 7 * 3, height: 2 * 13 * 3},
+		{name: "multi-byte characters count once", art: "é─\n", scale: 2, width: 2 * 7 * 2, height: 13 * 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := DefaultPNGOptions()
+			opts.Scale = tt.scale
+			var buf bytes.Buffer
+			if err := PNG(&buf, tt.art, opts); err != nil {
+				t.Fatal(err)
+			}
+			img, err := png.Decode(&buf)
+			if err != nil {
+				t.Fatalf("decoding the image: %v", err)
+			}
+			if got := img.Bounds().Size(); got.X != tt.width || got.Y != tt.height {
+				t.Errorf("image is %dx%d, want %dx%d", got.X, got.Y, tt.width, tt.height)
+			}
+		})
+	}
+}
+
+func TestPNGTooLarge(t *testing.T) {
+	opts := DefaultPNGOptions()
+	opts.Scale = MaxPNGScale
+	// 74 columns of 7 pixels at scale 8 is 4144 pixels, just over the limit
+	art := string(bytes.Repeat([]byte("a"), 74)) + "\n"
+	err := PNG(&bytes.Buffer{}, art, opts)
+	var tooLarge *ImageTooLargeError
+	if !errors.As(err, &tooLarge) || tooLarge.Width != 74*7*MaxPNGScale {
+		t.Errorf("PNG returned %v, want an ImageTooLargeError", err)
+	}
+}
@@ -0,0 +1,581 @@
+package asciiart
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Policies for characters the banner has no art for.
+const (
+	UnknownError = "error" // reject the input
+	UnknownSkip  = "skip"  // drop the character
+	UnknownSpace = "space" // render the character as a blank space glyph
+)
+
+// Horizontal alignments for the rendered lines.
+const (
+	AlignLeft    = "left"
+	AlignCenter  = "center"
+	AlignRight   = "right"
+	AlignJustify = "justify" // stretch the gaps between words to fill the width
+)
+
+// Frames drawn around the whole of the art.
+const (
+	BorderNone   = "none"
+	BorderSingle = "single" // box-drawing lines
+	BorderDouble = "double" // double box-drawing lines
+	BorderASCII  = "ascii"  // plus signs, dashes and bars
+)
+
+// borderStyle holds the characters of a frame: the top-left, top-right,
+// bottom-left and bottom-right corners, then the horizontal and vertical edges
+type borderStyle [6]string
+
+var borderStyles = map[string]borderStyle{
+	BorderSingle: {"┌", "┐", "└", "┘", "─", "│"},
+	BorderDouble: {"╔", "╗", "╚", "╝", "═", "║"},
+	BorderASCII:  {"+", "+", "+", "+", "-", "|"},
+}
+
+// DefaultTabWidth is the tab stop width used by DefaultOptions
+const DefaultTabWidth = 4
+
+// MaxTabWidth is the largest accepted tab stop width
+const MaxTabWidth = 16
+
+// MaxSpacing is the largest accepted number of blank columns between glyphs
+const MaxSpacing = 10
+
+// MaxLineSpacing is the largest accepted number of blank rows between lines
+const MaxLineSpacing = 5
+
+// MaxWidth is the largest accepted target width for aligned or wrapped output
+const MaxWidth = 1000
+
+// ErrTabsNotAllowed is returned when the text contains tabs and TabWidth is 0
+var ErrTabsNotAllowed = errors.New("Tabs are not allowed: please remove them or set a tab width above 0.")
+
+// Options controls how text is turned into ASCII art
+type Options struct {
+	Unknown     string // policy for characters the banner has no art for
+	Align       string // horizontal alignment of each rendered line
+	TabWidth    int    // columns between tab stops when expanding tabs; 0 rejects tabs
+	Width       int    // columns to center, right-align or justify within; 0 uses the widest rendered line
+	Wrap        int    // maximum width of the art in columns before lines wrap; 0 never wraps
+	Border      string // frame drawn around the whole of the art
+	Spacing     int    // blank columns between consecutive glyphs
+	LineSpacing int    // blank rows between the art for consecutive lines
+	Trim        bool   // strip trailing spaces from each row once the art is laid out
+	Transform   string // reflection applied to the art once it is aligned
+}
+
+// DefaultOptions returns the options used when a request sets none
+func DefaultOptions() Options {
+	return Options{Unknown: UnknownError, Align: AlignLeft, TabWidth: DefaultTabWidth, Border: BorderNone, Transform: TransformNone}
+}
+
+// Validate fills in defaults for unset options and rejects invalid values
+func (opts *Options) Validate() error {
+	defaults := DefaultOptions()
+	switch opts.Unknown {
+	case "":
+		opts.Unknown = defaults.Unknown
+	case UnknownError, UnknownSkip, UnknownSpace:
+	default:
+		return fmt.Errorf("Invalid unknown option %q: please use %s, %s or %s.", opts.Unknown, UnknownError, UnknownSkip, UnknownSpace)
+	}
+	switch opts.Align {
+	case "":
+		opts.Align = defaults.Align
+	case AlignLeft, AlignCenter, AlignRight, AlignJustify:
+	default:
+		return fmt.Errorf("Invalid align option %q: please use %s, %s, %s or %s.", opts.Align, AlignLeft, AlignCenter, AlignRight, AlignJustify)
+	}
+	switch opts.Border {
+	case "":
+		opts.Border = defaults.Border
+	case BorderNone, BorderSingle, BorderDouble, BorderASCII:
+	default:
+		return fmt.Errorf("Invalid border option %q: please use %s, %s, %s or %s.", opts.Border, BorderNone, BorderSingle, BorderDouble, BorderASCII)
+	}
+	if opts.TabWidth < 0 || opts.TabWidth > MaxTabWidth {
+		return fmt.Errorf("Invalid tabwidth %d: please use 0 to reject tabs or up to %d spaces.", opts.TabWidth, MaxTabWidth)
+	}
+	if opts.Transform == "" {
+		opts.Transform = defaults.Transform
+	} else if !slices.Contains(Transforms, opts.Transform) {
+		return fmt.Errorf("Invalid transform option %q: please use %s.", opts.Transform, strings.Join(Transforms, ", "))
+	}
+	if opts.Spacing < 0 || opts.Spacing > MaxSpacing {
+		return fmt.Errorf("Invalid spacing %d: please use 0 to %d columns.", opts.Spacing, MaxSpacing)
+	}
+	if opts.LineSpacing < 0 || opts.LineSpacing > MaxLineSpacing {
+		return fmt.Errorf("Invalid line spacing %d: please use 0 to %d rows.", opts.LineSpacing, MaxLineSpacing)
+	}
+	if opts.Width < 0 || opts.Width > MaxWidth {
+		return fmt.Errorf("Invalid width %d: please use 0 to fit the widest line or up to %d columns.", opts.Width, MaxWidth)
+	}
+	if opts.Wrap < 0 || opts.Wrap > MaxWidth {
+		return fmt.Errorf("Invalid wrap %d: please use 0 to never wrap or up to %d columns.", opts.Wrap, MaxWidth)
+	}
+	return nil
+}
+
+// WidthExceededError reports a rendered line wider than the target width
+type WidthExceededError struct {
+	Width  int // target width in columns
+	Needed int // width of the widest rendered line
+}
+
+func (e *WidthExceededError) Error() string {
+	return fmt.Sprintf("Text too wide: the art needs %d columns but the width is %d. Please shorten the line or increase the width.", e.Needed, e.Width)
+}
+
+// UnsupportedCharsError lists input characters the banner has no art for
+type UnsupportedCharsError struct {
+	Chars []rune
+}
+
+func (e *UnsupportedCharsError) Error() string {
+	quoted := make([]string, len(e.Chars))
+	for i, char := range e.Chars {
+		quoted[i] = strconv.QuoteRune(char)
+	}
+	return "Unsupported characters: " + strings.Join(quoted, ", ")
+}
+
+// NormalizeLineEndings converts Windows (\r\n) and old Mac (\r) line
+// endings to \n
+func NormalizeLineEndings(text string) string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	return strings.ReplaceAll(text, "\r", "\n")
+}
+
+// SplitLines splits text into lines, accepting any line ending
+func SplitLines(text string) []string {
+	return strings.Split(NormalizeLineEndings(text), "\n")
+}
+
+// Generate creates ASCII art for each input line using a font
+func Generate(font Font, userInput []string, opts Options) (string, error) {
+	fonts := make([]Font, len(userInput))
+	for i := range fonts {
+		fonts[i] = font
+	}
+	return GeneratePerLine(fonts, userInput, opts)
+}
+
+// GeneratePerLine creates ASCII art for each input line using the font at
+// the same index in fonts
+func GeneratePerLine(fonts []Font, userInput []string, opts Options) (string, error) {
+	blocks, err := renderBlocks(fonts, userInput, opts, "", 0)
+	if err != nil {
+		return "", err
+	}
+	return writeArt(blocks, opts, func(result *strings.Builder, b block, i int) {
+		result.WriteString(b.rows[i])
+	}), nil
+}
+
+// GenerateHighlighted creates ASCII art like GeneratePerLine, returned as
+// HTML in which the art for every occurrence of letters, or all of the art
+// when letters is empty, is wrapped in a span of the given CSS color. With
+// ColorRainbow each character takes the next color of the rainbow palette.
+func GenerateHighlighted(fonts []Font, userInput []string, opts Options, letters, color string) (template.HTML, error) {
+	palette := rainbowColors
+	if color != ColorRainbow {
+		if !IsCSSColor(color) {
+			return "", &InvalidColorError{Color: color}
+		}
+		palette = []string{color}
+	}
+	art, err := generatePainted(fonts, userInput, opts, letters, palette, htmlPainter{})
+	return template.HTML(art), err
+}
+
+// GenerateANSI creates ASCII art like GeneratePerLine in which the art for
+// every occurrence of letters, or all of the art when letters is empty, is
+// wrapped in ANSI escape sequences for color. With ColorRainbow each
+// character takes the next color of the rainbow palette.
+func GenerateANSI(fonts []Font, userInput []string, opts Options, letters, color string) (string, error) {
+	palette := rainbowANSI
+	if color != ColorRainbow {
+		code, ok := ansiColorCode(color)
+		if !ok {
+			return "", &InvalidColorError{Color: color, ANSI: true}
+		}
+		palette = []string{code}
+	}
+	return generatePainted(fonts, userInput, opts, letters, palette, ansiPainter{})
+}
+
+// generatePainted creates ASCII art in which p paints the columns for every
+// occurrence of letters with the colors of palette in turn, and writes every
+// other column plain. When letters is empty every character is painted.
+func generatePainted(fonts []Font, userInput []string, opts Options, letters string, palette []string, p painter) (string, error) {
+	blocks, err := renderBlocks(fonts, userInput, opts, letters, len(palette))
+	if err != nil {
+		return "", err
+	}
+	return writeArt(blocks, opts, func(result *strings.Builder, b block, i int) {
+		// A single color for all of the art paints whole rows
+		if letters == "" && len(palette) == 1 {
+			if b.rows[i] != "" {
+				result.WriteString(p.paint(b.rows[i], palette[0]))
+			}
+			return
+		}
+		row := []rune(b.rows[i])
+		for start := 0; start < len(row); {
+			// Write each run of columns in the same color in one go
+			end := start
+			for end < len(row) && b.paint(i, end) == b.paint(i, start) {
+				end++
+			}
+			if color := b.paint(i, start); color > 0 {
+				result.WriteString(p.paint(string(row[start:end]), palette[color-1]))
+			} else {
+				result.WriteString(p.plain(string(row[start:end])))
+			}
+			start = end
+		}
+	}), nil
+}
+
+// block is the art for one input line: font.Height rows and, when the art
+// is painted, the color of each column of each row
+type block struct {
+	rows   []string
+	paints [][]int // 1-based palette index of each column, or 0 for plain columns
+}
+
+// paint returns the palette color of a column of a row, or 0 if it is plain
+func (b block) paint(row, column int) int {
+	if b.paints == nil || column >= len(b.paints[row]) {
+		return 0
+	}
+	return b.paints[row][column]
+}
+
+// padLeft prefixes every row with n blank columns
+func (b block) padLeft(n int) {
+	for i, row := range b.rows {
+		b.rows[i] = strings.Repeat(" ", n) + row
+		if b.paints != nil {
+			b.paints[i] = append(make([]int, n), b.paints[i]...)
+		}
+	}
+}
+
+// renderBlocks wraps, checks and renders the input lines into aligned
+// blocks. When colors is above 0 the columns for letters are painted with a
+// palette of that many colors.
+func renderBlocks(fonts []Font, userInput []string, opts Options, letters string, colors int) ([]block, error) {
+	if len(fonts) != len(userInput) {
+		return nil, fmt.Errorf("got %d fonts for %d lines", len(fonts), len(userInput))
+	}
+	if opts.TabWidth == 0 && slices.ContainsFunc(userInput, func(line string) bool { return strings.Contains(line, "\t") }) {
+		return nil, ErrTabsNotAllowed
+	}
+	fonts, userInput = WrapLines(fonts, userInput, opts)
+	// Expand tabs and apply the policy for characters without art.
+	lines := make([]string, len(userInput))
+	var unsupported []rune
+	for i, line := range userInput {
+		lines[i] = expandTabs(line, opts.TabWidth)
+		for _, char := range lines[i] {
+			if _, ok := fonts[i].Glyphs[char]; !ok && !slices.Contains(unsupported, char) {
+				unsupported = append(unsupported, char)
+			}
+		}
+	}
+	if len(unsupported) > 0 && opts.Unknown == UnknownError {
+		return nil, &UnsupportedCharsError{Chars: unsupported}
+	}
+
+	// Render each input line into a block of rows
+	blocks := make([]block, len(lines))
+	paints := make([][]int, len(lines))
+	next := 0
+	for n, line := range lines {
+		if colors > 0 {
+			paints[n], next = paintChars(line, letters, colors, next)
+		}
+		blocks[n] = renderLine(fonts[n], []rune(line), paints[n], opts)
+	}
+	var err error
+	if opts.Align == AlignJustify {
+		err = justifyBlocks(fonts, lines, blocks, paints, opts)
+	} else {
+		err = alignBlocks(blocks, opts.Align, opts.Width)
+	}
+	if err != nil {
+		return nil, err
+	}
+	transformBlocks(blocks, opts)
+	return blocks, nil
+}
+
+// writeArt joins the blocks into the finished art, writing the content of
+// each row with writeRow. Every input line is a block of rows as tall as its
+// font, so an empty input line leaves a gap of that many blank rows, and
+// opts.LineSpacing blank rows separate consecutive blocks. A border other
+// than none frames the art, padding every row to the widest. With opts.Trim
+// the rows of unframed art lose their trailing spaces, so the frame's right
+// edge still lines up.
+func writeArt(blocks []block, opts Options, writeRow func(result *strings.Builder, b block, i int)) string {
+	var result strings.Builder
+	style, framed := borderStyles[opts.Border]
+	if opts.Trim && !framed {
+		for _, b := range blocks {
+			for i, row := range b.rows {
+				b.rows[i] = strings.TrimRight(row, " ")
+			}
+		}
+	}
+	width := 0
+	if framed {
+		for _, b := range blocks {
+			width = max(width, blockWidth(b.rows))
+		}
+		// The frame keeps one space between the art and each side
+		result.WriteString(style[0] + strings.Repeat(style[4], width+2) + style[1] + "\n")
+	}
+	for n, b := range blocks {
+		if n > 0 {
+			for range opts.LineSpacing {
+				if framed {
+					result.WriteString(style[5] + strings.Repeat(" ", width+2) + style[5])
+				}
+				result.WriteString("\n")
+			}
+		}
+		for i := range b.rows {
+			if framed {
+				result.WriteString(style[5] + " ")
+			}
+			writeRow(&result, b, i)
+			if framed {
+				result.WriteString(strings.Repeat(" ", width-utf8.RuneCountInString(b.rows[i])) + " " + style[5])
+			}
+			result.WriteString("\n")
+		}
+	}
+	if framed {
+		result.WriteString(style[2] + strings.Repeat(style[4], width+2) + style[3] + "\n")
+	}
+	return result.String()
+}
+
+// paintChars assigns each character of line a 1-based index into a palette
+// of colors, or 0 to leave it plain. The characters of every occurrence of
+// letters, or every character when letters is empty, take the colors in
+// turn starting from the next color; spaces stay plain. It returns the
+// colors and the next color to use.
+func paintChars(line, letters string, colors, next int) ([]int, int) {
+	chars := []rune(line)
+	target := []rune(letters)
+	marked := make([]bool, len(chars))
+	for i := range chars {
+		if len(target) == 0 {
+			marked[i] = true
+		} else if i+len(target) <= len(chars) && slices.Equal(chars[i:i+len(target)], target) {
+			for j := i; j < i+len(target); j++ {
+				marked[j] = true
+			}
+		}
+	}
+	paints := make([]int, len(chars))
+	for i, char := range chars {
+		if marked[i] && !unicode.IsSpace(char) {
+			paints[i] = next%colors + 1
+			next++
+		}
+	}
+	return paints, next
+}
+
+// renderLine renders a line of text into font.Height rows, with
+// opts.Spacing blank columns between glyphs, carrying each character's color
+// over to the columns of its art when paints is not nil
+func renderLine(font Font, line []rune, paints []int, opts Options) block {
+	b := block{rows: make([]string, font.Height)}
+	if paints != nil {
+		b.paints = make([][]int, font.Height)
+	}
+	for i := range b.rows {
+		var row strings.Builder
+		glyphs := 0
+		for k, char := range line {
+			art, ok := font.Glyphs[char]
+			if !ok {
+				if opts.Unknown == UnknownSkip {
+					continue
+				}
+				// A blank glyph keeps the following columns aligned
+				art = font.Glyphs[' ']
+			}
+			if glyphs > 0 && opts.Spacing > 0 {
+				row.WriteString(strings.Repeat(" ", opts.Spacing))
+				if paints != nil {
+					b.paints[i] = append(b.paints[i], make([]int, opts.Spacing)...)
+				}
+			}
+			glyphs++
+			row.WriteString(art[i])
+			if paints != nil {
+				for range utf8.RuneCountInString(art[i]) {
+					b.paints[i] = append(b.paints[i], paints[k])
+				}
+			}
+		}
+		b.rows[i] = row.String()
+	}
+	return b
+}
+
+// justifyBlocks re-renders each line of two or more words so the gaps
+// between words stretch it to exactly width columns, or to the widest
+// rendered line when width is 0. Extra columns that do not divide evenly go
+// to the leftmost gaps, one each. Lines of a single word stay left aligned.
+func justifyBlocks(fonts []Font, lines []string, blocks []block, paints [][]int, opts Options) error {
+	maxWidth := 0
+	for _, b := range blocks {
+		maxWidth = max(maxWidth, blockWidth(b.rows))
+	}
+	target := maxWidth
+	if opts.Width > 0 {
+		if maxWidth > opts.Width {
+			return &WidthExceededError{Width: opts.Width, Needed: maxWidth}
+		}
+		target = opts.Width
+	}
+	for n, line := range lines {
+		chars := []rune(line)
+		spans := wordSpans(chars)
+		if len(spans) < 2 {
+			continue
+		}
+		words := make([]block, len(spans))
+		used := 0
+		for i, span := range spans {
+			var wordPaints []int
+			if paints[n] != nil {
+				wordPaints = paints[n][span[0]:span[1]]
+			}
+			words[i] = renderLine(fonts[n], chars[span[0]:span[1]], wordPaints, opts)
+			used += blockWidth(words[i].rows)
+		}
+		gaps := len(words) - 1
+		extra := target - used
+		for i := range blocks[n].rows {
+			var row strings.Builder
+			var rowPaints []int
+			for w, word := range words {
+				if w > 0 {
+					pad := extra / gaps
+					if w <= extra%gaps {
+						pad++
+					}
+					row.WriteString(strings.Repeat(" ", pad))
+					rowPaints = append(rowPaints, make([]int, pad)...)
+				}
+				row.WriteString(word.rows[i])
+				if word.paints != nil {
+					rowPaints = append(rowPaints, word.paints[i]...)
+				}
+			}
+			blocks[n].rows[i] = row.String()
+			if blocks[n].paints != nil {
+				blocks[n].paints[i] = rowPaints
+			}
+		}
+	}
+	return nil
+}
+
+// wordSpans returns the start and end index of each run of non-space characters
+func wordSpans(chars []rune) [][2]int {
+	var spans [][2]int
+	start := -1
+	for i, char := range chars {
+		switch {
+		case unicode.IsSpace(char) && start >= 0:
+			spans = append(spans, [2]int{start, i})
+			start = -1
+		case !unicode.IsSpace(char) && start < 0:
+			start = i
+		}
+	}
+	if start >= 0 {
+		spans = append(spans, [2]int{start, len(chars)})
+	}
+	return spans
+}
+
+// alignBlocks pads the rows of each rendered line so it sits left, centered
+// or right within width columns, or within the widest rendered line when
+// width is 0
+func alignBlocks(blocks []block, align string, width int) error {
+	if align == AlignLeft {
+		return nil
+	}
+	maxWidth := 0
+	for _, b := range blocks {
+		maxWidth = max(maxWidth, blockWidth(b.rows))
+	}
+	if width > 0 {
+		if maxWidth > width {
+			return &WidthExceededError{Width: width, Needed: maxWidth}
+		}
+		maxWidth = width
+	}
+	for _, b := range blocks {
+		width := blockWidth(b.rows)
+		if width == 0 {
+			continue // Leave empty lines empty
+		}
+		pad := maxWidth - width
+		if align == AlignCenter {
+			pad /= 2
+		}
+		b.padLeft(pad)
+	}
+	return nil
+}
+
+// blockWidth returns the width in columns of the widest row in a block
+func blockWidth(rows []string) int {
+	width := 0
+	for _, row := range rows {
+		width = max(width, utf8.RuneCountInString(row))
+	}
+	return width
+}
+
+// expandTabs replaces each tab with spaces up to the next multiple of width
+func expandTabs(line string, width int) string {
+	if !strings.Contains(line, "\t") || width <= 0 {
+		return line
+	}
+	var expanded strings.Builder
+	column := 0
+	for _, char := range line {
+		if char == '\t' {
+			spaces := width - column%width
+			expanded.WriteString(strings.Repeat(" ", spaces))
+			column += spaces
+			continue
+		}
+		expanded.WriteRune(char)
+		column++
+	}
+	return expanded.String()
+}
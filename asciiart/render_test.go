@@ -0,0 +1,298 @@
+package asciiart
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestGenerate(t *testing.T) {
+	font := fixtureFont(t)
+	tests := []struct {
+		name    string
+		lines   []string
+		unknown string
+		want    string
+	}{
+		{name: "single character", lines: []string{"A"}, want: "AA\n__\n"},
+		{name: "several characters", lines: []string{"Hi!"}, want: "HHii!!\n______\n"},
+		{name: "multiple lines", lines: []string{"a", "b"}, want: "aa\n__\nbb\n__\n"},
+		{name: "spaces", lines: []string{"a b"}, want: "aa  bb\n__  __\n"},
+		{name: "only a space", lines: []string{" "}, want: "  \n  \n"},
+		{name: "empty line between lines", lines: []string{"a", "", "b"}, want: "aa\n__\n\n\nbb\n__\n"},
+		{name: "unknown character as space", lines: []string{"a€b"}, unknown: UnknownSpace, want: "aa  bb\n__  __\n"},
+		{name: "unknown character skipped", lines: []string{"a€b"}, unknown: UnknownSkip, want: "aabb\n____\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := DefaultOptions()
+			if tt.unknown != "" {
+				opts.Unknown = tt.unknown
+			}
+			got, err := Generate(font, tt.lines, opts)
+			if err != nil {
+				t.Fatalf("Generate(%q) returned error: %v", tt.lines, err)
+			}
+			if got != tt.want {
+				t.Errorf("Generate(%q) =\n%s\nwant\n%s", tt.lines, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenerateRejectsUnknownCharacters(t *testing.T) {
+	_, err := Generate(fixtureFont(t), []string{"a€b√"}, DefaultOptions())
+	var unsupported *UnsupportedCharsError
+	if !errors.As(err, &unsupported) {
+		t.Fatalf("Generate returned %v, want an UnsupportedCharsError", err)
+	}
+	if want := "Unsupported characters: '€', '√'"; err.Error() != want {
+		t.Errorf("error = %q, want %q", err.Error(), want)
+	}
+}
+
+func TestSplitLines(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{text: "ab\ncd", want: []string{"ab", "cd"}},
+		{text: "ab\r\ncd", want: []string{"ab", "cd"}},
+		{text: "ab\rcd", want: []string{"ab", "cd"}},
+		{text: "ab\r\n\r\ncd\r\n", want: []string{"ab", "", "cd", ""}},
+	}
+	for _, tt := range tests {
+		if got := SplitLines(tt.text); !slices.Equal(got, tt.want) {
+			t.Errorf("SplitLines(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestUnknownPolicies(t *testing.T) {
+	font := fixtureFont(t)
+	lines := []string{"a€b", "ü!"}
+	tests := []struct {
+		unknown string
+		want    string
+		wantErr string
+	}{
+		{unknown: UnknownError, wantErr: "Unsupported characters: '€', 'ü'"},
+		{unknown: UnknownSkip, want: "aabb\n____\n!!\n__\n"},
+		// The blank glyph is as wide as a space, so the columns after it stay aligned
+		{unknown: UnknownSpace, want: "aa  bb\n__  __\n  !!\n  __\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.unknown, func(t *testing.T) {
+			opts := DefaultOptions()
+			opts.Unknown = tt.unknown
+			got, err := Generate(font, lines, opts)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("Generate returned %v, want error %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("Generate =\n%s\n%v\nwant\n%s", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpandTabs(t *testing.T) {
+	tests := []struct {
+		name  string
+		line  string
+		width int
+		want  string
+	}{
+		{name: "line start", line: "\tab", width: 4, want: "    ab"},
+		{name: "middle", line: "a\tb", width: 4, want: "a   b"},
+		{name: "at a tab stop", line: "abcd\te", width: 4, want: "abcd    e"},
+		{name: "consecutive", line: "a\t\tb", width: 4, want: "a       b"},
+		{name: "other width", line: "\ta\tb", width: 2, want: "  a b"},
+		{name: "no tabs", line: "ab", width: 4, want: "ab"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := expandTabs(tt.line, tt.width); got != tt.want {
+				t.Errorf("expandTabs(%q, %d) = %q, want %q", tt.line, tt.width, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenerateTabs(t *testing.T) {
+	font := fixtureFont(t)
+	opts := DefaultOptions()
+	opts.TabWidth = 2
+	got, err := Generate(font, []string{"\ta"}, opts)
+	if want := "    aa\n    __\n"; err != nil || got != want {
+		t.Errorf("Generate with a tab = %q, %v, want %q", got, err, want)
+	}
+	opts.TabWidth = 0
+	if _, err := Generate(font, []string{"a\tb"}, opts); !errors.Is(err, ErrTabsNotAllowed) {
+		t.Errorf("Generate with tabs rejected returned %v, want %v", err, ErrTabsNotAllowed)
+	}
+}
+
+func TestGenerateEmptyLines(t *testing.T) {
+	// An empty line is a gap as tall as the font, so every input line takes
+	// the same number of rows
+	font := standardFont(t)
+	tests := []struct {
+		name string
+		text string
+	}{
+		{name: "newline", text: "\n"},
+		{name: "trailing-newline", text: "hello\n"},
+		{name: "leading-newline", text: "\nhello"},
+		{name: "blank-line-between", text: "a\n\nb"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Generate(font, SplitLines(tt.text), DefaultOptions())
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkGolden(t, "empty-lines-"+tt.name+".txt", got)
+		})
+	}
+}
+
+func TestGenerateAlignment(t *testing.T) {
+	font := standardFont(t)
+	for _, align := range []string{AlignLeft, AlignCenter, AlignRight} {
+		t.Run(align, func(t *testing.T) {
+			opts := DefaultOptions()
+			opts.Align = align
+			opts.Width = 40
+			got, err := Generate(font, []string{"hi", "there"}, opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkGolden(t, "align-"+align+".txt", got)
+		})
+	}
+}
+
+func TestGenerateAlignmentTooWide(t *testing.T) {
+	opts := DefaultOptions()
+	opts.Align = AlignCenter
+	opts.Width = 20
+	_, err := Generate(standardFont(t), []string{"hi", "there"}, opts)
+	var tooWide *WidthExceededError
+	if !errors.As(err, &tooWide) || tooWide.Width != 20 || tooWide.Needed != 35 {
+		t.Errorf("Generate returned %v, want a WidthExceededError needing 35 of 20 columns", err)
+	}
+}
+
+func TestGenerateJustify(t *testing.T) {
+	font := fixtureFont(t)
+	tests := []struct {
+		name  string
+		line  string
+		width int
+		want  string
+	}{
+		{name: "even", line: "a b c", width: 14, want: "aa    bb    cc"},
+		{name: "one left over", line: "a b c", width: 13, want: "aa    bb   cc"},
+		{name: "two left over", line: "a b c d", width: 19, want: "aa    bb    cc   dd"},
+		{name: "fewer columns than gaps", line: "a b c d", width: 16, want: "aa   bb   cc  dd"},
+		{name: "single word", line: "abc", width: 10, want: "aabbcc"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := DefaultOptions()
+			opts.Align = AlignJustify
+			opts.Width = tt.width
+			got, err := Generate(font, []string{tt.line}, opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// Every row takes the same gaps, so the bottom row is the top one underlined
+			underline := func(r rune) rune {
+				if r == ' ' {
+					return r
+				}
+				return '_'
+			}
+			want := tt.want + "\n" + strings.Map(underline, tt.want) + "\n"
+			if got != want {
+				t.Errorf("justified %q to %d columns =\n%s\nwant\n%s", tt.line, tt.width, got, want)
+			}
+		})
+	}
+}
+
+func TestGenerateEmptyLineGap(t *testing.T) {
+	tests := []struct {
+		name   string
+		height int
+		lines  []string
+		want   string
+	}{
+		{name: "two rows", height: 2, lines: []string{"a", "", "b"}, want: "aa\n__\n\n\nbb\n__\n"},
+		{name: "three rows", height: 3, lines: []string{"a", "", "b"}, want: "aa\n__\n__\n\n\n\nbb\n__\n__\n"},
+		{name: "only empty lines", height: 2, lines: []string{"", ""}, want: "\n\n\n\n"},
+		{name: "consecutive", height: 2, lines: []string{"a", "", "", "b"}, want: "aa\n__\n\n\n\n\nbb\n__\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			font, err := LoadFont(strings.NewReader(fixtureBanner(tt.height, "\n")))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := Generate(font, tt.lines, DefaultOptions())
+			if err != nil || got != tt.want {
+				t.Errorf("Generate(%q) = %q, %v, want %q", tt.lines, got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenerateBorders(t *testing.T) {
+	font := standardFont(t)
+	for _, border := range []string{BorderNone, BorderSingle, BorderDouble, BorderASCII} {
+		t.Run(border, func(t *testing.T) {
+			opts := DefaultOptions()
+			opts.Border = border
+			got, err := Generate(font, []string{"hi", "", "you"}, opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkGolden(t, "border-"+border+".txt", got)
+		})
+	}
+}
+
+func TestGenerateSpacing(t *testing.T) {
+	font := fixtureFont(t)
+	tests := []struct {
+		spacing int
+		want    string
+	}{
+		{spacing: 0, want: "aabbcc\n______\n"},
+		{spacing: 1, want: "aa bb cc\n__ __ __\n"},
+		{spacing: 3, want: "aa   bb   cc\n__   __   __\n"},
+	}
+	for _, tt := range tests {
+		opts := DefaultOptions()
+		opts.Spacing = tt.spacing
+		got, err := Generate(font, []string{"abc"}, opts)
+		if err != nil || got != tt.want {
+			t.Errorf("Generate with spacing %d = %q, %v, want %q", tt.spacing, got, err, tt.want)
+		}
+	}
+}
+
+func TestValidateSpacing(t *testing.T) {
+	for spacing, valid := range map[int]bool{-1: false, 0: true, MaxSpacing: true, MaxSpacing + 1: false} {
+		opts := DefaultOptions()
+		opts.Spacing = spacing
+		if err := opts.Validate(); (err == nil) != valid {
+			t.Errorf("Validate with spacing %d returned %v, want valid %v", spacing, err, valid)
+		}
+	}
+}
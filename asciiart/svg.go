@@ -0,0 +1,87 @@
+package asciiart
+
+import (
+	"encoding/xml"
+	"fmt"
+	"math"
+	"strings"
+	"unicode/utf8"
+)
+
+// Bounds for SVGOptions.FontSize.
+const (
+	MinSVGFontSize = 6
+	MaxSVGFontSize = 72
+)
+
+// svgPadding is the margin in pixels around the art
+const svgPadding = 10
+
+// SVGOptions controls how art is drawn as an SVG image
+type SVGOptions struct {
+	FontSize   int    // font size in pixels
+	Foreground string // CSS color of the art
+	Background string // CSS color behind the art
+}
+
+// DefaultSVGOptions returns the SVG options used when a request sets none
+func DefaultSVGOptions() SVGOptions {
+	return SVGOptions{FontSize: 14, Foreground: "black", Background: "white"}
+}
+
+// Validate fills in defaults for unset options and rejects invalid values
+func (opts *SVGOptions) Validate() error {
+	defaults := DefaultSVGOptions()
+	if opts.FontSize == 0 {
+		opts.FontSize = defaults.FontSize
+	}
+	if opts.FontSize < MinSVGFontSize || opts.FontSize > MaxSVGFontSize {
+		return fmt.Errorf("Invalid font size %d: please use %d to %d pixels.", opts.FontSize, MinSVGFontSize, MaxSVGFontSize)
+	}
+	if opts.Foreground == "" {
+		opts.Foreground = defaults.Foreground
+	}
+	if opts.Background == "" {
+		opts.Background = defaults.Background
+	}
+	for _, color := range []string{opts.Foreground, opts.Background} {
+		if !IsCSSColor(color) {
+			return &InvalidColorError{Color: color}
+		}
+	}
+	return nil
+}
+
+// SVG draws rendered art as an SVG document with one monospace text element
+// per row. The image is sized from the widest row and the number of rows,
+// taking a monospace character to be 0.6 of the font size wide.
+func SVG(art string, opts SVGOptions) string {
+	rows := strings.Split(strings.TrimSuffix(art, "\n"), "\n")
+	columns := 0
+	for _, row := range rows {
+		columns = max(columns, utf8.RuneCountInString(row))
+	}
+	size := float64(opts.FontSize)
+	lineHeight := math.Ceil(size * 1.2)
+	width := int(math.Ceil(float64(columns)*size*0.6)) + 2*svgPadding
+	height := int(lineHeight)*len(rows) + 2*svgPadding
+
+	var svg strings.Builder
+	fmt.Fprintf(&svg, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n", width, height, width, height)
+	fmt.Fprintf(&svg, `<rect width="100%%" height="100%%" fill="%s"/>`+"\n", escapeXML(opts.Background))
+	fmt.Fprintf(&svg, `<g font-family="monospace" font-size="%d" fill="%s" xml:space="preserve">`+"\n", opts.FontSize, escapeXML(opts.Foreground))
+	for i, row := range rows {
+		// y is the baseline of the row, one font size below its top
+		y := svgPadding + float64(i)*lineHeight + size
+		fmt.Fprintf(&svg, `<text x="%d" y="%g">%s</text>`+"\n", svgPadding, y, escapeXML(row))
+	}
+	svg.WriteString("</g>\n</svg>\n")
+	return svg.String()
+}
+
+// escapeXML escapes text for use in XML character data and attribute values
+func escapeXML(text string) string {
+	var escaped strings.Builder
+	xml.EscapeText(&escaped, []byte(text))
+	return escaped.String()
+}
@@ -0,0 +1,61 @@
+package asciiart
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestSVGOptionsValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    SVGOptions
+		want    SVGOptions
+		wantErr bool
+	}{
+		{name: "defaults", opts: SVGOptions{}, want: DefaultSVGOptions()},
+		{name: "smallest font", opts: SVGOptions{FontSize: MinSVGFontSize}, want: SVGOptions{FontSize: MinSVGFontSize, Foreground: "black", Background: "white"}},
+		{name: "largest font", opts: SVGOptions{FontSize: MaxSVGFontSize}, want: SVGOptions{FontSize: MaxSVGFontSize, Foreground: "black", Background: "white"}},
+		{name: "font too small", opts: SVGOptions{FontSize: MinSVGFontSize - 1}, wantErr: true},
+		{name: "font too large", opts: SVGOptions{FontSize: MaxSVGFontSize + 1}, wantErr: true},
+		{name: "colors kept", opts: SVGOptions{Foreground: "#00ff00", Background: "navy"}, want: SVGOptions{FontSize: 14, Foreground: "#00ff00", Background: "navy"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := tt.opts
+			err := opts.Validate()
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Validate accepted %+v", tt.opts)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if opts != tt.want {
+				t.Errorf("Validate gave %+v, want %+v", opts, tt.want)
+			}
+		})
+	}
+}
+
+func TestSVGOptionsInvalidColor(t *testing.T) {
+	opts := SVGOptions{Background: "red;}"}
+	var badColor *InvalidColorError
+	if err := opts.Validate(); !errors.As(err, &badColor) || badColor.Color != "red;}" {
+		t.Errorf("Validate returned %v, want an InvalidColorError", err)
+	}
+}
+
+func TestSVGEscapesText(t *testing.T) {
+	svg := SVG("a<b\nc&d\n", DefaultSVGOptions())
+	for _, want := range []string{`>a&lt;b</text>`, `>c&amp;d</text>`} {
+		if !strings.Contains(svg, want) {
+			t.Errorf("SVG does not contain %s:\n%s", want, svg)
+		}
+	}
+	if got := strings.Count(svg, "<text "); got != 2 {
+		t.Errorf("SVG has %d text elements, want 2", got)
+	}
+}
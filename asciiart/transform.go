@@ -0,0 +1,104 @@
+package asciiart
+
+import (
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// Transforms applied to the finished art.
+const (
+	TransformNone      = "none"
+	TransformMirror    = "mirror"    // flip left to right
+	TransformFlip      = "flip"      // flip each line upside down
+	TransformRotate180 = "rotate180" // turn the whole of the art upside down
+)
+
+// Transforms lists the valid transform options in the order they are offered
+var Transforms = []string{TransformNone, TransformMirror, TransformFlip, TransformRotate180}
+
+// reflections pairs up the direction-sensitive characters that turn into
+// each other when reflected left to right (mirror), top to bottom (flip) or
+// both
+var reflections = []struct {
+	a, b   rune
+	mirror bool
+	flip   bool
+}{
+	{'(', ')', true, false},
+	{'[', ']', true, false},
+	{'{', '}', true, false},
+	{'<', '>', true, false},
+	{'/', '\\', true, true},
+	{'^', 'v', false, true},
+}
+
+// reflectChar returns the character that looks like char reflected left to
+// right when mirror is set and top to bottom when flip is set
+func reflectChar(char rune, mirror, flip bool) rune {
+	for _, r := range reflections {
+		// Reflecting both ways swaps a pair only if just one of the reflections does
+		if (mirror && r.mirror) == (flip && r.flip) {
+			continue
+		}
+		switch char {
+		case r.a:
+			return r.b
+		case r.b:
+			return r.a
+		}
+	}
+	return char
+}
+
+// transformBlocks applies the transform named by opts.Transform to the
+// rendered and aligned blocks, which it may reorder
+func transformBlocks(blocks []block, opts Options) {
+	switch opts.Transform {
+	case TransformMirror:
+		reflectBlocks(blocks, true, false)
+	case TransformFlip:
+		reflectBlocks(blocks, false, true)
+	case TransformRotate180:
+		// Turning the art upside down also puts the last line first
+		slices.Reverse(blocks)
+		reflectBlocks(blocks, true, true)
+	}
+}
+
+// reflectBlocks reflects the art left to right when mirror is set and turns
+// the art for each line upside down when flip is set. Before mirroring,
+// every row is padded to the width of the widest row so the whole of the
+// art turns about one axis. The lines themselves keep their order, and any
+// frame is drawn around the reflected art, which looks the same as a
+// reflected frame.
+func reflectBlocks(blocks []block, mirror, flip bool) {
+	width := 0
+	for _, b := range blocks {
+		width = max(width, blockWidth(b.rows))
+	}
+	for _, b := range blocks {
+		if flip {
+			slices.Reverse(b.rows)
+			slices.Reverse(b.paints)
+		}
+		for i, row := range b.rows {
+			if mirror {
+				row += strings.Repeat(" ", width-utf8.RuneCountInString(row))
+			}
+			chars := []rune(row)
+			if mirror {
+				slices.Reverse(chars)
+			}
+			for k, char := range chars {
+				chars[k] = reflectChar(char, mirror, flip)
+			}
+			b.rows[i] = string(chars)
+			if mirror && b.paints != nil {
+				paints := append(b.paints[i], make([]int, width-len(b.paints[i]))...)
+				slices.Reverse(paints)
+				b.paints[i] = paints
+			}
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package asciiart
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestGenerateMirror(t *testing.T) {
+	opts := DefaultOptions()
+	opts.Transform = TransformMirror
+	got, err := Generate(standardFont(t), []string{"(ab>", "c/"}, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkGolden(t, "transform-mirror.txt", got)
+}
+
+func TestGenerateFlip(t *testing.T) {
+	opts := DefaultOptions()
+	opts.Transform = TransformFlip
+	got, err := Generate(standardFont(t), []string{"^ab/", "c"}, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkGolden(t, "transform-flip.txt", got)
+}
+
+func TestRotate180Twice(t *testing.T) {
+	font := standardFont(t)
+	lines := []string{"(a/b)", "<c^d>", "{e}"}
+	opts := DefaultOptions()
+	blocks, err := renderBlocks([]Font{font, font, font}, lines, opts, "", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := blockRows(blocks)
+	opts.Transform = TransformRotate180
+	transformBlocks(blocks, opts)
+	if slices.Equal(blockRows(blocks), want) {
+		t.Fatal("rotate180 left the art unchanged")
+	}
+	transformBlocks(blocks, opts)
+	if got := blockRows(blocks); !slices.Equal(got, want) {
+		t.Errorf("rotate180 twice =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestReflectCharTwice(t *testing.T) {
+	for char := ' '; char <= '~'; char++ {
+		for _, way := range [][2]bool{{true, false}, {false, true}, {true, true}} {
+			if got := reflectChar(reflectChar(char, way[0], way[1]), way[0], way[1]); got != char {
+				t.Errorf("reflecting %q twice (mirror %v, flip %v) = %q", char, way[0], way[1], got)
+			}
+		}
+	}
+}
+
+// blockRows returns the rows of all the blocks in order. Mirroring pads
+// every row to the widest one, so trailing spaces are dropped.
+func blockRows(blocks []block) []string {
+	var rows []string
+	for _, b := range blocks {
+		for _, row := range b.rows {
+			rows = append(rows, strings.TrimRight(row, " "))
+		}
+	}
+	return rows
+}
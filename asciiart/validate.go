@@ -0,0 +1,45 @@
+package asciiart
+
+import (
+	"fmt"
+	"strings"
+)
+
+// InvalidChar is a character that cannot be rendered, with its 1-based
+// position in the submitted text
+type InvalidChar struct {
+	Char     rune
+	Position int
+}
+
+// InvalidTextError lists every character in the text that cannot be rendered
+type InvalidTextError struct {
+	Chars []InvalidChar
+}
+
+func (e *InvalidTextError) Error() string {
+	parts := make([]string, len(e.Chars))
+	for i, invalid := range e.Chars {
+		parts[i] = fmt.Sprintf("unsupported character %q at position %d", invalid.Char, invalid.Position)
+	}
+	return "Invalid text: " + strings.Join(parts, ", ")
+}
+
+// ValidateText checks that every character is printable ASCII (32 to 126),
+// a line break or a tab, and returns an *InvalidTextError naming the
+// offending characters otherwise
+func ValidateText(text string) error {
+	var invalid []InvalidChar
+	position := 0
+	for _, char := range text {
+		position++
+		if (char >= 32 && char <= 126) || char == '\n' || char == '\r' || char == '\t' {
+			continue
+		}
+		invalid = append(invalid, InvalidChar{Char: char, Position: position})
+	}
+	if len(invalid) > 0 {
+		return &InvalidTextError{Chars: invalid}
+	}
+	return nil
+}
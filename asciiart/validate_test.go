@@ -0,0 +1,51 @@
+package asciiart
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestValidateText(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []InvalidChar
+	}{
+		{name: "space", text: " "},
+		{name: "tilde", text: "~"},
+		{name: "line breaks and tabs", text: "a\r\nb\tc\n"},
+		{name: "unit separator", text: "a\x1f", want: []InvalidChar{{Char: 31, Position: 2}}},
+		{name: "delete", text: "\x7f", want: []InvalidChar{{Char: 127, Position: 1}}},
+		{name: "null", text: "\x00", want: []InvalidChar{{Char: 0, Position: 1}}},
+		{name: "two-byte", text: "héllo", want: []InvalidChar{{Char: 'é', Position: 2}}},
+		{name: "three-byte", text: "1€", want: []InvalidChar{{Char: '€', Position: 2}}},
+		{name: "four-byte", text: "🙂!🙂", want: []InvalidChar{{Char: '🙂', Position: 1}, {Char: '🙂', Position: 3}}},
+		{name: "positions count characters, not bytes", text: "é€x\x7f", want: []InvalidChar{{Char: 'é', Position: 1}, {Char: '€', Position: 2}, {Char: 127, Position: 4}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateText(tt.text)
+			if tt.want == nil {
+				if err != nil {
+					t.Errorf("ValidateText(%q) = %v, want nil", tt.text, err)
+				}
+				return
+			}
+			var invalid *InvalidTextError
+			if !errors.As(err, &invalid) {
+				t.Fatalf("ValidateText(%q) = %v, want an InvalidTextError", tt.text, err)
+			}
+			if !slices.Equal(invalid.Chars, tt.want) {
+				t.Errorf("ValidateText(%q) chars = %v, want %v", tt.text, invalid.Chars, tt.want)
+			}
+		})
+	}
+}
+
+func TestInvalidTextErrorMessage(t *testing.T) {
+	err := ValidateText("héllo\x7f")
+	if want := `Invalid text: unsupported character 'é' at position 2, unsupported character '\x7f' at position 6`; err == nil || err.Error() != want {
+		t.Errorf("error = %v, want %q", err, want)
+	}
+}
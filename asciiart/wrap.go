@@ -0,0 +1,113 @@
+package asciiart
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// WrapLines breaks each line whose rendered art would be wider than
+// opts.Wrap columns into several lines at spaces. Words that are wider than
+// the limit on their own are broken between characters. The returned fonts
+// line up with the returned lines, so each wrapped piece keeps the font of
+// the line it came from. When opts.Wrap is 0 the lines are returned
+// unchanged.
+func WrapLines(fonts []Font, lines []string, opts Options) ([]Font, []string) {
+	if opts.Wrap <= 0 || len(fonts) != len(lines) {
+		return fonts, lines
+	}
+	var wrappedFonts []Font
+	var wrappedLines []string
+	for i, line := range lines {
+		// Tabs are measured as the spaces they expand to
+		line = expandTabs(line, opts.TabWidth)
+		for _, piece := range wrapLine(fonts[i], line, opts) {
+			wrappedFonts = append(wrappedFonts, fonts[i])
+			wrappedLines = append(wrappedLines, piece)
+		}
+	}
+	return wrappedFonts, wrappedLines
+}
+
+// wrapLine splits a line into pieces whose rendered art fits in opts.Wrap
+// columns. The line is split on single spaces, so runs of spaces keep every
+// space but the one a piece breaks at.
+func wrapLine(font Font, line string, opts Options) []string {
+	width := opts.Wrap
+	if textWidth(font, line, opts) <= width {
+		return []string{line}
+	}
+	var wrapped []string
+	current, started := "", false
+	for _, word := range strings.Split(line, " ") {
+		candidate := word
+		if started {
+			candidate = current + " " + word
+		}
+		if textWidth(font, candidate, opts) <= width {
+			current, started = candidate, true
+			continue
+		}
+		if started {
+			wrapped = append(wrapped, current)
+		}
+		// Break words that do not fit on a line of their own between characters
+		for textWidth(font, word, opts) > width {
+			n := fittingChars(font, word, opts)
+			wrapped = append(wrapped, word[:n])
+			word = word[n:]
+		}
+		current, started = word, true
+	}
+	// A break at the last space of the line leaves nothing to wrap
+	if current != "" || len(wrapped) == 0 {
+		wrapped = append(wrapped, current)
+	}
+	return wrapped
+}
+
+// fittingChars returns the length in bytes of the longest prefix of word
+// whose art fits in opts.Wrap columns, always taking at least one character
+func fittingChars(font Font, word string, opts Options) int {
+	for i := 0; i < len(word); {
+		// Invalid UTF-8 bytes are one byte long, not the length of U+FFFD
+		_, size := utf8.DecodeRuneInString(word[i:])
+		if textWidth(font, word[:i+size], opts) > opts.Wrap {
+			if i == 0 {
+				return size
+			}
+			return i
+		}
+		i += size
+	}
+	return len(word)
+}
+
+// textWidth returns the width in columns of the art for text, including
+// opts.Spacing columns between each pair of glyphs
+func textWidth(font Font, text string, opts Options) int {
+	width, glyphs := 0, 0
+	for _, char := range text {
+		if _, ok := font.Glyphs[char]; !ok && opts.Unknown == UnknownSkip {
+			continue
+		}
+		width += glyphWidth(font, char, opts.Unknown)
+		glyphs++
+	}
+	if glyphs > 1 {
+		width += opts.Spacing * (glyphs - 1)
+	}
+	return width
+}
+
+// glyphWidth returns the width in columns of the art for a character,
+// following the policy for characters the font has no art for
+func glyphWidth(font Font, char rune, unknown string) int {
+	art, ok := font.Glyphs[char]
+	if !ok {
+		if unknown == UnknownSkip {
+			return 0
+		}
+		art = font.Glyphs[' ']
+	}
+	return blockWidth(art)
+}
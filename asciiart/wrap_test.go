@@ -0,0 +1,66 @@
+package asciiart
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestWrapLines(t *testing.T) {
+	font := fixtureFont(t)
+	tests := []struct {
+		name    string
+		line    string
+		wrap    int
+		unknown string
+		want    []string
+	}{
+		{name: "fits", line: "ab cd", wrap: 10, want: []string{"ab cd"}},
+		{name: "breaks at spaces", line: "ab cd ef", wrap: 10, want: []string{"ab cd", "ef"}},
+		{name: "breaks long words", line: "abcdef", wrap: 4, want: []string{"ab", "cd", "ef"}},
+		{name: "keeps indentation", line: "  ab cd", wrap: 8, want: []string{"  ab", "cd"}},
+		{name: "keeps runs of spaces", line: "ab   cd ef", wrap: 14, want: []string{"ab   cd", "ef"}},
+		{name: "breaks inside a run of spaces", line: "ab    cd", wrap: 8, want: []string{"ab  ", " cd"}},
+		{name: "invalid UTF-8 as space", line: "\xffab", wrap: 2, unknown: UnknownSpace, want: []string{"\xff", "a", "b"}},
+		{name: "invalid UTF-8 skipped", line: "a\xffbc", wrap: 4, unknown: UnknownSkip, want: []string{"a\xffb", "c"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := DefaultOptions()
+			opts.Wrap = tt.wrap
+			if tt.unknown != "" {
+				opts.Unknown = tt.unknown
+			}
+			_, got := WrapLines([]Font{font}, []string{tt.line}, opts)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("WrapLines(%q, %d) = %q, want %q", tt.line, tt.wrap, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWrapLinesEdgeSpaces(t *testing.T) {
+	font := fixtureFont(t)
+	tests := []struct {
+		name string
+		line string
+		wrap int
+		want []string
+	}{
+		{name: "fitting line keeps both ends", line: "  ab  ", wrap: 20, want: []string{"  ab  "}},
+		// Only the space a piece breaks at is dropped
+		{name: "only spaces", line: "   ", wrap: 4, want: []string{"  "}},
+		{name: "indentation on the first piece only", line: "  ab cd ef", wrap: 10, want: []string{"  ab", "cd ef"}},
+		{name: "trailing spaces kept when wrapping", line: "ab cd  ", wrap: 8, want: []string{"ab", "cd  "}},
+		{name: "indentation too wide for the first word", line: "    abcd", wrap: 8, want: []string{"   ", "abcd"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := DefaultOptions()
+			opts.Wrap = tt.wrap
+			_, got := WrapLines([]Font{font}, []string{tt.line}, opts)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("WrapLines(%q, %d) = %q, want %q", tt.line, tt.wrap, got, tt.want)
+			}
+		})
+	}
+}
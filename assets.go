@@ -0,0 +1,67 @@
+package main
+
+import (
+	"embed"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// embeddedAssets bundles the banner fonts, HTML templates and static files
+// into the binary so it runs from any directory.
+//
+//go:embed ART HTML/*.html static
+var embeddedAssets embed.FS
+
+// assets is the filesystem the banners, templates and static files are read
+// from. It defaults to the embedded files and can point at an on-disk
+// directory for development with the -assets flag.
+var assets fs.FS = embeddedAssets
+
+// useAssetDir switches asset loading to an on-disk directory when one is given
+func useAssetDir(dir string) error {
+	if dir == "" {
+		return nil
+	}
+	if err := checkDir(dir); err != nil {
+		return err
+	}
+	assets = os.DirFS(dir)
+	return nil
+}
+
+// bannerFS returns the directory banner files are read from: the on-disk
+// directory dir when one is given, or else the ART directory of the assets
+func bannerFS(dir string) (fs.FS, error) {
+	if dir == "" {
+		return fs.Sub(assets, "ART")
+	}
+	if err := checkDir(dir); err != nil {
+		return nil, err
+	}
+	return os.DirFS(dir), nil
+}
+
+// uploadDir returns the on-disk banner directory uploaded banners are saved
+// to, or "" when banners come from the embedded files
+func uploadDir(cfg config) string {
+	switch {
+	case cfg.BannerDir != "":
+		return cfg.BannerDir
+	case cfg.AssetDir != "":
+		return filepath.Join(cfg.AssetDir, "ART")
+	}
+	return ""
+}
+
+// checkDir reports an error unless dir exists and is a directory
+func checkDir(dir string) error {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return &fs.PathError{Op: "open", Path: dir, Err: fs.ErrInvalid}
+	}
+	return nil
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"io"
+
+	"ASCII/asciiart"
+)
+
+// runCLI renders text in a banner to out, like the original ascii-art
+// command line tool: \n in the text is a line break and \\ a backslash
+func runCLI(out io.Writer, text, banner string) error {
+	text, err := asciiart.Unescape(text)
+	if err != nil {
+		return err
+	}
+	if text == "" {
+		return nil
+	}
+	opts := asciiart.DefaultOptions()
+	opts.TabWidth = tabWidth
+	result, err := renderBannerArt([]string{banner}, text, opts)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(out, result)
+	return err
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"ASCII/asciiart"
+)
+
+// maxCompareBytes caps the combined size of the art in a comparison of every banner
+const maxCompareBytes = 1 << 20
+
+// errCompareTooLarge is returned when a comparison would exceed maxCompareBytes
+var errCompareTooLarge = fmt.Errorf("Comparison too large: the art in every banner would exceed %d bytes. Please shorten the text.", maxCompareBytes)
+
+// comparison is the art for the text in one banner
+type comparison struct {
+	Banner string
+	Result string
+}
+
+// compareResponse is the JSON body returned for a comparison, mapping each banner to its art
+type compareResponse struct {
+	Results map[string]string `json:"results"`
+}
+
+// compareBanners renders text in every available banner, in banner order,
+// reading the fonts from the banner cache
+func compareBanners(text string, opts asciiart.Options) ([]comparison, error) {
+	var comparisons []comparison
+	size := 0
+	for _, banner := range availableBanners() {
+		result, err := renderBannerArt([]string{banner}, text, opts)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", banner, err)
+		}
+		size += len(result)
+		if size > maxCompareBytes {
+			return nil, errCompareTooLarge
+		}
+		comparisons = append(comparisons, comparison{Banner: banner, Result: result})
+	}
+	return comparisons, nil
+}
+
+// compareFailure maps a comparison error to a message and status code
+func compareFailure(err error) (string, int) {
+	if errors.Is(err, errCompareTooLarge) {
+		return err.Error(), http.StatusRequestEntityTooLarge
+	}
+	return renderFailure(err)
+}
+
+// writeComparisons writes the comparisons as plain text, each under its banner name
+func writeComparisons(w io.Writer, comparisons []comparison) {
+	for i, c := range comparisons {
+		if i > 0 {
+			io.WriteString(w, "\n")
+		}
+		io.WriteString(w, c.Banner+"\n"+strings.Repeat("=", len(c.Banner))+"\n"+c.Result)
+	}
+}
+
+// comparisonResults maps each banner in comparisons to its art
+func comparisonResults(comparisons []comparison) map[string]string {
+	results := make(map[string]string, len(comparisons))
+	for _, c := range comparisons {
+		results[c.Banner] = c.Result
+	}
+	return results
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"ASCII/asciiart"
+)
+
+// defaultPort is used when neither the -port flag nor PORT is set
+const defaultPort = "8080"
+
+// Input limits used when the corresponding flags are not set.
+const (
+	defaultMaxTextLength       = 1000
+	defaultMaxLineLength       = 200
+	defaultMaxLines            = 100
+	defaultMaxBodyBytes  int64 = 64 << 10
+)
+
+// defaultRateLimit is the number of art requests per minute allowed from
+// each client IP when RATE_LIMIT is not set
+const defaultRateLimit = 10
+
+// config holds the settings read from command-line flags and the environment
+type config struct {
+	Port              string
+	AssetDir          string
+	BannerDir         string // on-disk directory of banner files; empty uses ART from the assets
+	AdminToken        string // bearer token for the admin endpoints; empty disables them
+	ReadHeaderTimeout time.Duration
+	ReadTimeout       time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	MaxHeaderBytes    int
+	TabWidth          int
+	MaxTextLength     int
+	MaxLineLength     int
+	MaxLines          int
+	MaxBodyBytes      int64
+	MaxArtWidth       int
+	StrictBanners     bool   // refuse to start if any banner fails to load
+	CLI               bool   // render CLIText to stdout and exit instead of serving
+	CLIText           string // text to render in CLI mode
+	CLIBanner         string // banner to render CLIText in
+	RateLimit         int    // art requests per minute per client IP; 0 disables the limit
+	TrustProxy        bool   // rate limit by X-Forwarded-For instead of the connection address
+}
+
+// loadConfig parses the command-line flags into a config
+func loadConfig() (config, error) {
+	var cfg config
+	// Timeouts default to their environment variables, and flags override both
+	timeouts := map[string]time.Duration{"READ_HEADER_TIMEOUT": 5 * time.Second, "READ_TIMEOUT": 10 * time.Second, "WRITE_TIMEOUT": 15 * time.Second, "IDLE_TIMEOUT": 60 * time.Second}
+	for name, def := range timeouts {
+		value, err := envDuration(name, def)
+		if err != nil || value <= 0 {
+			return cfg, fmt.Errorf("invalid %s %q: must be a positive duration such as 15s", name, os.Getenv(name))
+		}
+		timeouts[name] = value
+	}
+	portFlag := flag.String("port", "", "port to listen on (overrides the PORT environment variable, default "+defaultPort+")")
+	flag.StringVar(&cfg.AssetDir, "assets", "", "serve banners, templates and CSS from this directory instead of the embedded copies")
+	flag.DurationVar(&cfg.ReadHeaderTimeout, "read-header-timeout", timeouts["READ_HEADER_TIMEOUT"], "maximum time to read request headers (overrides READ_HEADER_TIMEOUT)")
+	flag.DurationVar(&cfg.ReadTimeout, "read-timeout", timeouts["READ_TIMEOUT"], "maximum time to read the whole request, including the body (overrides READ_TIMEOUT)")
+	flag.DurationVar(&cfg.WriteTimeout, "write-timeout", timeouts["WRITE_TIMEOUT"], "maximum time to write the response (overrides WRITE_TIMEOUT)")
+	flag.DurationVar(&cfg.IdleTimeout, "idle-timeout", timeouts["IDLE_TIMEOUT"], "maximum time to keep an idle keep-alive connection open (overrides IDLE_TIMEOUT)")
+	flag.IntVar(&cfg.MaxHeaderBytes, "max-header-bytes", 1<<20, "maximum size of request headers in bytes")
+	flag.IntVar(&cfg.TabWidth, "tab-width", asciiart.DefaultTabWidth, "default number of spaces between tab stops when expanding tabs in the input (0 rejects tabs)")
+	flag.IntVar(&cfg.MaxTextLength, "max-text-length", defaultMaxTextLength, "maximum number of characters accepted in the text input")
+	flag.IntVar(&cfg.MaxLineLength, "max-line-length", defaultMaxLineLength, "maximum number of characters per line of text input")
+	flag.IntVar(&cfg.MaxLines, "max-lines", defaultMaxLines, "maximum number of lines of text input")
+	flag.Int64Var(&cfg.MaxBodyBytes, "max-body-bytes", defaultMaxBodyBytes, "maximum size of a request body in bytes")
+	flag.IntVar(&cfg.MaxArtWidth, "max-art-width", 0, "maximum width of the art in columns; longer lines wrap onto the next line (0 means unlimited)")
+	flag.BoolVar(&cfg.StrictBanners, "strict-banners", false, "refuse to start if any banner file is invalid, instead of leaving it out")
+	flag.StringVar(&cfg.CLIText, "cli", "", "render this text to stdout and exit instead of starting the server")
+	flag.StringVar(&cfg.CLIBanner, "banner", defaultBanner, "banner to render the -cli text in")
+	flag.Parse()
+	// CLI mode is on whenever -cli is given, even with empty text
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == "cli" {
+			cfg.CLI = true
+		}
+	})
+
+	if cfg.TabWidth < 0 || cfg.TabWidth > asciiart.MaxTabWidth {
+		return cfg, fmt.Errorf("invalid tab width %d: must be between 0 (reject tabs) and %d", cfg.TabWidth, asciiart.MaxTabWidth)
+	}
+	if cfg.MaxTextLength < 1 {
+		return cfg, fmt.Errorf("invalid max text length %d: must be at least 1", cfg.MaxTextLength)
+	}
+	if cfg.MaxLineLength < 1 {
+		return cfg, fmt.Errorf("invalid max line length %d: must be at least 1", cfg.MaxLineLength)
+	}
+	if cfg.MaxLines < 1 {
+		return cfg, fmt.Errorf("invalid max lines %d: must be at least 1", cfg.MaxLines)
+	}
+	if cfg.MaxArtWidth < 0 || cfg.MaxArtWidth > asciiart.MaxWidth {
+		return cfg, fmt.Errorf("invalid max art width %d: must be between 0 (unlimited) and %d", cfg.MaxArtWidth, asciiart.MaxWidth)
+	}
+	if cfg.MaxBodyBytes < 1 {
+		return cfg, fmt.Errorf("invalid max body bytes %d: must be at least 1", cfg.MaxBodyBytes)
+	}
+
+	rateLimit, err := envInt("RATE_LIMIT", defaultRateLimit)
+	if err != nil || rateLimit < 0 {
+		return cfg, fmt.Errorf("invalid RATE_LIMIT %q: must be a number of requests per minute, or 0 to disable the limit", os.Getenv("RATE_LIMIT"))
+	}
+	cfg.RateLimit = rateLimit
+	cfg.BannerDir = os.Getenv("BANNER_DIR")
+	cfg.AdminToken = os.Getenv("ADMIN_TOKEN")
+	if value := os.Getenv("TRUST_PROXY"); value != "" {
+		if cfg.TrustProxy, err = strconv.ParseBool(value); err != nil {
+			return cfg, fmt.Errorf("invalid TRUST_PROXY %q: must be true or false", value)
+		}
+	}
+
+	port, err := resolvePort(*portFlag)
+	if err != nil {
+		return cfg, err
+	}
+	cfg.Port = port
+	return cfg, nil
+}
+
+// resolvePort picks the listen port with the precedence flag > PORT environment variable > default
+func resolvePort(flagPort string) (string, error) {
+	port := flagPort
+	if port == "" {
+		port = os.Getenv("PORT")
+	}
+	if port == "" {
+		port = defaultPort
+	}
+	// Validate that the port is a number in the TCP port range
+	n, err := strconv.Atoi(port)
+	if err != nil || n < 1 || n > 65535 {
+		return "", fmt.Errorf("invalid port %q: must be a number between 1 and 65535", port)
+	}
+	return port, nil
+}
+
+// envInt reads an integer environment variable, returning def when it is unset
+func envInt(name string, def int) (int, error) {
+	value := os.Getenv(name)
+	if value == "" {
+		return def, nil
+	}
+	return strconv.Atoi(value)
+}
+
+// envDuration reads a duration environment variable, returning def when it is unset
+func envDuration(name string, def time.Duration) (time.Duration, error) {
+	value := os.Getenv(name)
+	if value == "" {
+		return def, nil
+	}
+	return time.ParseDuration(value)
+}
@@ -0,0 +1,36 @@
+package main
+
+import "testing"
+
+func TestResolvePort(t *testing.T) {
+	tests := []struct {
+		name    string
+		flag    string
+		env     string
+		want    string
+		wantErr bool
+	}{
+		{name: "default", want: defaultPort},
+		{name: "environment", env: "9000", want: "9000"},
+		{name: "flag", flag: "9001", want: "9001"},
+		{name: "flag over environment", flag: "9001", env: "9000", want: "9001"},
+		{name: "not a number", flag: "http", wantErr: true},
+		{name: "out of range", env: "70000", wantErr: true},
+		{name: "zero", flag: "0", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("PORT", tt.env)
+			got, err := resolvePort(tt.flag)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("resolvePort(%q) with PORT=%q = %q, want an error", tt.flag, tt.env, got)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("resolvePort(%q) with PORT=%q = %q, %v, want %q", tt.flag, tt.env, got, err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"ASCII/asciiart"
+)
+
+// exportFormat is a file format the art can be downloaded in
+type exportFormat struct {
+	contentType string
+	extension   string
+	// write encodes the art to w using the options in the form. Invalid
+	// options are returned as *exportOptionError.
+	write func(w io.Writer, r *http.Request, form artForm, art string) error
+}
+
+// exportFormats maps the values of the format field to their formats
+var exportFormats = map[string]exportFormat{
+	"txt":  {contentType: "text/plain; charset=utf-8", extension: "txt", write: writeText},
+	"svg":  {contentType: "image/svg+xml", extension: "svg", write: writeSVG},
+	"png":  {contentType: "image/png", extension: "png", write: writePNG},
+	"pdf":  {contentType: "application/pdf", extension: "pdf", write: writePDF},
+	"html": {contentType: "text/html; charset=utf-8", extension: "html", write: writeHTML},
+}
+
+// exportFormatNames lists the accepted format values for error messages
+var exportFormatNames = []string{"txt", "svg", "png", "pdf", "html"}
+
+// exportOptionError reports an invalid export option in the form
+type exportOptionError struct {
+	err error
+}
+
+func (e *exportOptionError) Error() string {
+	return e.err.Error()
+}
+
+func (e *exportOptionError) Unwrap() error {
+	return e.err
+}
+
+// exportFailure maps an error from an export format to the message and status code for the client
+func exportFailure(err error) (string, int) {
+	var option *exportOptionError
+	var badColor *asciiart.InvalidColorError
+	var tooLarge *asciiart.ImageTooLargeError
+	switch {
+	case errors.As(err, &option), errors.As(err, &badColor):
+		return err.Error(), http.StatusBadRequest
+	case errors.As(err, &tooLarge):
+		return err.Error(), http.StatusRequestEntityTooLarge
+	default:
+		log.Printf("Error exporting art: %v", err)
+		return "Internal Server Error: Failed to create the file", http.StatusInternalServerError
+	}
+}
+
+// exportFilename derives a file name from the first words of the input
+// text, falling back to ascii-art
+func exportFilename(text string) string {
+	var name strings.Builder
+	dash := false
+	for _, char := range strings.ToLower(text) {
+		if name.Len() >= 40 {
+			break
+		}
+		if char <= unicode.MaxASCII && (unicode.IsLetter(char) || unicode.IsDigit(char)) {
+			if dash && name.Len() > 0 {
+				name.WriteByte('-')
+			}
+			name.WriteRune(char)
+			dash = false
+		} else {
+			dash = true
+		}
+	}
+	if name.Len() == 0 {
+		return "ascii-art"
+	}
+	return name.String()
+}
+
+// formInt reads an optional whole-number field, returning 0 when it is empty
+func formInt(r *http.Request, field, unit string) (int, error) {
+	value := r.FormValue(field)
+	if value == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, &exportOptionError{fmt.Errorf("Invalid %s %q: please use a whole number%s.", field, value, unit)}
+	}
+	return n, nil
+}
+
+// writeText writes the art as plain text, colored for terminals when asked
+// unless the color is meant for chosen letters in the web view
+func writeText(w io.Writer, r *http.Request, form artForm, art string) error {
+	color, letters := r.FormValue("color"), r.FormValue("letters")
+	if color != "" && (letters == "" || r.FormValue("colormode") == asciiart.ColorModeANSI) {
+		colored, err := asciiart.RenderANSI(form.Banners, asciiart.SplitLines(form.Text), form.Options, letters, color)
+		if err != nil {
+			return err
+		}
+		art = colored
+	}
+	_, err := io.WriteString(w, art)
+	return err
+}
+
+// writeSVG writes the art as an SVG image drawn with the font size and colors from the form
+func writeSVG(w io.Writer, r *http.Request, form artForm, art string) error {
+	opts := asciiart.SVGOptions{Foreground: r.FormValue("foreground"), Background: r.FormValue("background")}
+	var err error
+	if opts.FontSize, err = formInt(r, "fontsize", " of pixels"); err != nil {
+		return err
+	}
+	if err := opts.Validate(); err != nil {
+		return &exportOptionError{err}
+	}
+	_, err = io.WriteString(w, asciiart.SVG(art, opts))
+	return err
+}
+
+// writePNG writes the art as a PNG image drawn with the scale and colors from the form
+func writePNG(w io.Writer, r *http.Request, form artForm, art string) error {
+	opts := asciiart.PNGOptions{Foreground: r.FormValue("foreground"), Background: r.FormValue("background")}
+	var err error
+	if opts.Scale, err = formInt(r, "scale", ""); err != nil {
+		return err
+	}
+	if err := opts.Validate(); err != nil {
+		return &exportOptionError{err}
+	}
+	return asciiart.PNG(w, art, opts)
+}
+
+// writePDF writes the art as a PDF document laid out with the font size and orientation from the form
+func writePDF(w io.Writer, r *http.Request, form artForm, art string) error {
+	opts := asciiart.PDFOptions{Orientation: r.FormValue("orientation")}
+	var err error
+	if opts.FontSize, err = formInt(r, "fontsize", " of points"); err != nil {
+		return err
+	}
+	if err := opts.Validate(); err != nil {
+		return &exportOptionError{err}
+	}
+	return asciiart.PDF(w, art, opts)
+}
+
+// htmlExport is the data rendered by the export template
+type htmlExport struct {
+	Title      string
+	Art        string
+	Foreground string
+	Background string
+}
+
+// writeHTML writes the art as a standalone HTML document in the colors from the form
+func writeHTML(w io.Writer, r *http.Request, form artForm, art string) error {
+	page := htmlExport{Title: form.Text, Art: art, Foreground: r.FormValue("foreground"), Background: r.FormValue("background")}
+	if page.Foreground == "" {
+		page.Foreground = "black"
+	}
+	if page.Background == "" {
+		page.Background = "white"
+	}
+	for _, color := range []string{page.Foreground, page.Background} {
+		if !asciiart.IsCSSColor(color) {
+			return &exportOptionError{&asciiart.InvalidColorError{Color: color}}
+		}
+	}
+	return currentTemplates().export.Execute(w, page)
+}
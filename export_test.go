@@ -0,0 +1,98 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestDownloadSVG(t *testing.T) {
+	tests := []struct {
+		name       string
+		form       string
+		wantStatus int
+		wantBody   string
+	}{
+		{name: "defaults", form: "", wantStatus: http.StatusOK, wantBody: `font-size="14" fill="black"`},
+		{name: "smallest font", form: "&fontsize=6", wantStatus: http.StatusOK, wantBody: `font-size="6"`},
+		{name: "largest font", form: "&fontsize=72", wantStatus: http.StatusOK, wantBody: `font-size="72"`},
+		{name: "font too small", form: "&fontsize=5", wantStatus: http.StatusBadRequest, wantBody: "Invalid font size 5"},
+		{name: "font too large", form: "&fontsize=73", wantStatus: http.StatusBadRequest, wantBody: "Invalid font size 73"},
+		{name: "font not a number", form: "&fontsize=big", wantStatus: http.StatusBadRequest, wantBody: "please use a whole number of pixels"},
+		{name: "colors", form: "&foreground=%23ff0000&background=navy", wantStatus: http.StatusOK, wantBody: `<rect width="100%" height="100%" fill="navy"/>`},
+		{name: "invalid color", form: "&foreground=red%3B", wantStatus: http.StatusBadRequest, wantBody: "Invalid color"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve("POST", "/download", "text=a%3Cb&banner=standard&format=svg"+tt.form)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("download returned %d, want %d\n%s", rec.Code, tt.wantStatus, rec.Body)
+			}
+			if !strings.Contains(rec.Body.String(), tt.wantBody) {
+				t.Errorf("body does not contain %q:\n%s", tt.wantBody, rec.Body)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			if got := rec.Header().Get("Content-Type"); got != "image/svg+xml" {
+				t.Errorf("Content-Type = %q, want image/svg+xml", got)
+			}
+			if got, want := rec.Header().Get("Content-Disposition"), `attachment; filename="a-b.svg"`; got != want {
+				t.Errorf("Content-Disposition = %q, want %q", got, want)
+			}
+			// The standard banner is 8 rows high
+			if strings.Count(rec.Body.String(), "<text ") != 8 {
+				t.Errorf("SVG does not have a text element per row:\n%s", rec.Body)
+			}
+		})
+	}
+}
+
+func TestDownloadHTML(t *testing.T) {
+	tests := []struct {
+		name       string
+		form       string
+		wantStatus int
+		wantBody   []string
+	}{
+		{name: "default colors", form: "", wantStatus: http.StatusOK, wantBody: []string{"background-color: white;", "color: black;"}},
+		{name: "colors", form: "&foreground=%23ff0000&background=navy", wantStatus: http.StatusOK, wantBody: []string{"background-color: navy;", "color: #ff0000;"}},
+		{name: "invalid foreground", form: "&foreground=red%3B%7D", wantStatus: http.StatusBadRequest, wantBody: []string{"Invalid color"}},
+		{name: "invalid background", form: "&background=url(x)", wantStatus: http.StatusBadRequest, wantBody: []string{"Invalid color"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The standard banner draws & with < and > in it
+			rec := serve("POST", "/download", "text=a%26b&banner=standard&format=html"+tt.form)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("download returned %d, want %d\n%s", rec.Code, tt.wantStatus, rec.Body)
+			}
+			body := rec.Body.String()
+			for _, want := range tt.wantBody {
+				if !strings.Contains(body, want) {
+					t.Errorf("body does not contain %q:\n%s", want, body)
+				}
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			if got := rec.Header().Get("Content-Disposition"); got != `attachment; filename="a-b.html"` {
+				t.Errorf("Content-Disposition = %q", got)
+			}
+			// The document stands alone: nothing is loaded from elsewhere
+			for _, external := range []string{"<link", "stylesheet", "src=", "@import"} {
+				if strings.Contains(body, external) {
+					t.Errorf("document contains %q:\n%s", external, body)
+				}
+			}
+			for _, want := range []string{"<title>a&amp;b</title>", "| (_&gt;  &lt;"} {
+				if !strings.Contains(body, want) {
+					t.Errorf("document does not escape the art and title, missing %q:\n%s", want, body)
+				}
+			}
+			if strings.Contains(body, "(_>") {
+				t.Errorf("document contains unescaped art:\n%s", body)
+			}
+		})
+	}
+}
@@ -1,182 +1,1076 @@
 package main
 
 import (
-	"bufio"
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"html/template"
+	"io"
+	"io/fs"
 	"log"
+	"maps"
+	"mime"
+	"mime/multipart"
+	"net"
 	"net/http"
 	"os"
-	"path/filepath"
+	"os/signal"
+	"path"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
+	"syscall"
+	"time"
+	"unicode/utf8"
+
+	"ASCII/asciiart"
+)
+
+// supportedBanners lists the banner fonts that can be requested. It is
+// discovered from the banner files in ART/, or BANNER_DIR when set, at
+// startup (and on rescan), so adding a banner only means dropping its file
+// into that directory.
+var (
+	supportedBanners   []string
+	supportedBannersMu sync.RWMutex
+)
+
+// tabWidth is the number of columns between tab stops when expanding tabs in the input.
+var tabWidth = asciiart.DefaultTabWidth
+
+// maxTextLength is the maximum number of characters accepted in the text input.
+var maxTextLength = defaultMaxTextLength
+
+// maxBodyBytes caps the size of request bodies so oversized submissions are not buffered.
+var maxBodyBytes int64 = defaultMaxBodyBytes
+
+// maxLineLength and maxLines limit the shape of the text input.
+var (
+	maxLineLength = defaultMaxLineLength
+	maxLines      = defaultMaxLines
 )
 
+// maxArtWidth caps the width of the art in columns, wrapping longer lines; 0 leaves it unlimited.
+var maxArtWidth int
+
+// pageTemplates holds the parsed HTML templates
+type pageTemplates struct {
+	home      *template.Template
+	errorPage *template.Template
+	export    *template.Template
+}
+
+// templateFiles lists the template files in the order they are parsed
+var templateFiles = []string{"HTML/home.html", "HTML/error.html", "HTML/export.html"}
+
+// Parsed HTML templates, loaded at startup by loadTemplates and replaced
+// by /admin/reload.
+var (
+	templates   pageTemplates
+	templatesMu sync.RWMutex
+)
+
+// defaultBanner is preselected in the banner dropdown
+const defaultBanner = "standard"
+
+// homePage is the data rendered by the home template
+type homePage struct {
+	Text           string // the submitted text, to fill the form in again
+	Result         string
+	Highlighted    template.HTML // the result with the chosen letters colored, when there are any
+	Banners        []string
+	BannerHeights  map[string]int // rows in each banner's glyphs, for hints in the banner list
+	Selected       string
+	BannerUsed     string       // the banners the result was rendered in, when one was picked at random
+	ReverseArt     string       // the art submitted to be turned back into text
+	ReversedBanner string       // the banner the reversed art matched
+	Comparisons    []comparison // the text in every banner, when asked to compare them
+	Options        asciiart.Options
+	Escape         bool
+	Case           string
+	Limits         inputLimits
+	Colors         []string // basic colors suggested for the color field
+	Color          string
+	Letters        string
+	ColorMode      string // "ansi" when downloads color the chosen letters too
+}
+
+// inputLimits are the input size limits shown on the home page
+type inputLimits struct {
+	MaxTextLength int
+	MaxLineLength int
+	MaxLines      int
+}
+
+// currentLimits returns the configured input size limits
+func currentLimits() inputLimits {
+	return inputLimits{MaxTextLength: maxTextLength, MaxLineLength: maxLineLength, MaxLines: maxLines}
+}
+
+// templateFuncs are the helper functions available to the HTML templates
+var templateFuncs = template.FuncMap{
+	// title capitalises the first letter of a banner name for display
+	"title": func(s string) string {
+		if s == "" {
+			return s
+		}
+		return strings.ToUpper(s[:1]) + s[1:]
+	},
+}
+
 // Main function - entry point of the application
 func main() {
+	// Read command-line flags.
+	cfg, err := loadConfig()
+	if err != nil {
+		log.Fatal("Error reading configuration: ", err)
+	}
+
+	// Apply the rendering settings.
+	tabWidth = cfg.TabWidth
+	maxTextLength = cfg.MaxTextLength
+	maxBodyBytes = cfg.MaxBodyBytes
+	maxLineLength = cfg.MaxLineLength
+	maxLines = cfg.MaxLines
+	maxArtWidth = cfg.MaxArtWidth
+
+	// Switch to on-disk assets when a development directory is given.
+	if err := useAssetDir(cfg.AssetDir); err != nil {
+		log.Fatal("Error opening asset directory: ", err)
+	}
+	bannerDir, err := bannerFS(cfg.BannerDir)
+	if err != nil {
+		log.Fatal("Error opening banner directory: ", err)
+	}
+	// Banners from the assets are the built-in ones; BANNER_DIR holds custom banners
+	source := asciiart.SourceBuiltin
+	if cfg.BannerDir != "" {
+		source = asciiart.SourceCustom
+	}
+	asciiart.UseBannerFS(bannerDir, source)
+	bannerUploadDir = uploadDir(cfg)
+	adminToken = cfg.AdminToken
+
+	// In CLI mode, print the art and exit without starting the server.
+	if cfg.CLI {
+		if err := runCLI(os.Stdout, cfg.CLIText, cfg.CLIBanner); err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Parse the HTML templates, refusing to start if any of them is broken.
+	if err := loadTemplates(); err != nil {
+		log.Fatal("Error loading templates: ", err)
+	}
+
 	// Set up URL routes to their corresponding handlers.
-	http.HandleFunc("/", Serverouter)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", Serverouter)
+
+	// Discover the available banners and check them by loading them into the cache before accepting requests.
+	invalid, err := rescanBanners()
+	if err != nil {
+		log.Fatal("Error scanning banner directory: ", err)
+	}
+	if len(invalid) > 0 && cfg.StrictBanners {
+		log.Fatalf("Refusing to start with invalid banners: %s", strings.Join(invalid, ", "))
+	}
+	if len(availableBanners()) == 0 {
+		log.Print("Warning: no valid banners found; art requests will fail")
+	}
 
-	// Start an HTTP server listening on port 8080.
-	log.Println("Starting server on http://localhost:8080")
-	if err := http.ListenAndServe(":8080", nil); err != nil {
-		log.Fatal("Error starting server: ", err)
+	// Bind the port first so a busy or forbidden port produces a clear message.
+	listener, err := net.Listen("tcp", ":"+cfg.Port)
+	if err != nil {
+		log.Fatalf("Error starting server: cannot listen on port %s (is it already in use?): %v", cfg.Port, err)
+	}
+
+	// Start an HTTP server on the bound port in the background
+	server := newServer(cfg, mux)
+	go func() {
+		log.Printf("Starting server on http://localhost:%s", cfg.Port)
+		if err := server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			log.Fatal("Error starting server: ", err)
+		}
+	}()
+
+	// Wait for an interrupt or termination signal, then let in-flight requests finish.
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	<-stop
+	log.Println("shutting down gracefully")
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		log.Printf("Error during shutdown: %v", err)
 	}
 }
 
+// newServer wraps handler in the middleware and returns a server with
+// timeouts, so slow or stalled clients cannot hold connections open forever
+func newServer(cfg config, handler http.Handler) *http.Server {
+	if cfg.RateLimit > 0 {
+		handler = rateLimitMiddleware(newRateLimiter(cfg.RateLimit, cfg.TrustProxy), handler)
+	}
+	return &http.Server{
+		Handler:           loggingMiddleware(handler),
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+		ReadTimeout:       cfg.ReadTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+		MaxHeaderBytes:    cfg.MaxHeaderBytes,
+	}
+}
+
+// loadTemplates parses the HTML templates used by the handlers
+func loadTemplates() error {
+	parsed, problems := parseTemplates()
+	for _, file := range templateFiles {
+		if err := problems[file]; err != nil {
+			return err
+		}
+	}
+	useTemplates(parsed)
+	return nil
+}
+
+// parseTemplates parses every template file, returning the errors keyed by
+// file name
+func parseTemplates() (pageTemplates, map[string]error) {
+	parsed := make(map[string]*template.Template)
+	problems := make(map[string]error)
+	for _, file := range templateFiles {
+		tmpl, err := template.New(path.Base(file)).Funcs(templateFuncs).ParseFS(assets, file)
+		if err != nil {
+			problems[file] = err
+			continue
+		}
+		parsed[file] = tmpl
+	}
+	return pageTemplates{home: parsed["HTML/home.html"], errorPage: parsed["HTML/error.html"], export: parsed["HTML/export.html"]}, problems
+}
+
+// useTemplates replaces the parsed templates
+func useTemplates(parsed pageTemplates) {
+	templatesMu.Lock()
+	defer templatesMu.Unlock()
+	templates = parsed
+}
+
+// currentTemplates returns the parsed templates in use
+func currentTemplates() pageTemplates {
+	templatesMu.RLock()
+	defer templatesMu.RUnlock()
+	return templates
+}
+
 // Serverouter handles routing for different URL paths
 func Serverouter(w http.ResponseWriter, r *http.Request) {
 	switch r.URL.Path {
 	case "/":
-		serveHome(w, r)
+		// The home page renders art when prefilled, so it is compressed like the art pages
+		gzipMiddleware(http.HandlerFunc(serveHome)).ServeHTTP(w, r)
 	case "/ascii-art":
-		asciiArtHandler(w, r)
+		gzipMiddleware(http.HandlerFunc(asciiArtHandler)).ServeHTTP(w, r)
+	case "/download":
+		gzipMiddleware(http.HandlerFunc(downloadHandler)).ServeHTTP(w, r)
+	case "/preview":
+		previewHandler(w, r)
+	case "/decode":
+		decodeHandler(w, r)
+	case "/ascii-art/reverse":
+		reverseHandler(w, r)
+	case "/api/ascii-art":
+		gzipMiddleware(http.HandlerFunc(asciiArtAPIHandler)).ServeHTTP(w, r)
+	case "/api/banners":
+		bannersAPIHandler(w, r)
+	case "/api/banners/validate":
+		validateBannerAPIHandler(w, r)
+	case "/health":
+		healthHandler(w, r)
+	case "/version":
+		versionHandler(w, r)
+	case "/metrics":
+		metricsHandler.ServeHTTP(w, r)
+	case "/admin/banners", "/upload-banner":
+		adminBannersHandler(w, r)
+	case "/admin/reload":
+		adminReloadHandler(w, r)
 	case "/style.css":
 		serveCSS(w, r)
 	default:
-		// Redirect to home page for any undefined routes
-		renderError(w, "Method not allowed", http.StatusMethodNotAllowed) // 405 status code
+		// Serve static assets such as CSS, JavaScript and images
+		if strings.HasPrefix(r.URL.Path, "/static/") {
+			serveStatic(w, r)
+			return
+		}
+		// Unknown paths are not found; 405 is reserved for known paths hit with the wrong method
+		renderError(w, "Page not found", http.StatusNotFound) // 404 status code
 	}
 }
 
 // serveHome handles requests for the home page
 func serveHome(w http.ResponseWriter, r *http.Request) {
-	// Check if the request method is GET
-	if r.Method != "GET" {
-		renderError(w, "Method not allowed", http.StatusMethodNotAllowed)
+	// Check if the request method is GET or HEAD
+	if r.Method != "GET" && r.Method != "HEAD" {
+		renderMethodNotAllowed(w, "GET", "HEAD")
 		return
 	}
-	// Parse and execute the home template
-	tmpl, err := template.ParseFiles("HTML/home.html")
-	if err != nil {
-		renderError(w, "Internal Server Error: Failed to load template", http.StatusInternalServerError)
+	// Links with both text and banner show the art straight away
+	if homeRendersArt(r) {
+		asciiArtHandler(w, r)
 		return
 	}
-	// Handle any errors that occur during template parsing or execution
-	err = tmpl.Execute(w, map[string]string{"Result": ""})
+	query := r.URL.Query()
+	// Prefill the form from the query string, if given
+	selected := defaultBanner
+	if banner := query.Get("banner"); banner != "" {
+		selected = banner
+	}
+	// Execute the home template; for HEAD the server discards the body
+	page := homePage{Text: query.Get("text"), Banners: availableBanners(), BannerHeights: bannerHeights(), Selected: selected, Options: defaultFormOptions(), Limits: currentLimits(), Colors: asciiart.Colors}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	err := currentTemplates().home.Execute(w, page)
 	if err != nil {
 		renderError(w, "Internal Server Error: Failed to render template", http.StatusInternalServerError)
 		return
 	}
 }
 
+// homeRendersArt reports whether a request for the home page shows art,
+// which GET links with both text and banner do
+func homeRendersArt(r *http.Request) bool {
+	query := r.URL.Query()
+	return r.Method == "GET" && query.Get("text") != "" && query.Get("banner") != ""
+}
+
 // serveCSS handles requests for the CSS file
 func serveCSS(w http.ResponseWriter, r *http.Request) {
+	// Check if the request method is GET or HEAD
+	if r.Method != "GET" && r.Method != "HEAD" {
+		renderMethodNotAllowed(w, "GET", "HEAD")
+		return
+	}
+	// Serve the CSS file
+	http.ServeFileFS(w, r, assets, "static/style.css")
+}
+
+// serveStatic serves files from the static directory under /static/
+func serveStatic(w http.ResponseWriter, r *http.Request) {
 	// Check if the request method is GET
 	if r.Method != "GET" {
-		renderError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		renderMethodNotAllowed(w, "GET")
 		return
 	}
-	// Serve the CSS file
-	path := filepath.Join(".", "style.css")
-	http.ServeFile(w, r, path)
+	// Directory listings are not exposed
+	if strings.HasSuffix(r.URL.Path, "/") {
+		renderError(w, "Page not found", http.StatusNotFound)
+		return
+	}
+	static, err := fs.Sub(assets, "static")
+	if err != nil {
+		renderError(w, "Internal Server Error: Failed to open static files", http.StatusInternalServerError)
+		return
+	}
+	http.StripPrefix("/static/", http.FileServerFS(static)).ServeHTTP(w, r)
 }
 
-// asciiArtHandler processes requests for ASCII art generation
+// maxQueryLength caps the query string accepted by GET /ascii-art
+const maxQueryLength = 8192
+
+// Output formats of the art page, chosen with the format field
+const (
+	formatHTML  = "html"  // the home page with the art in it, the default
+	formatPlain = "plain" // the art as plain text
+	formatJSON  = "json"  // the art as {"result": "..."}
+)
+
+// artResult is the JSON body returned by the art page with format=json
+type artResult struct {
+	Result string `json:"result"`
+}
+
+// asciiArtHandler processes requests for ASCII art generation. GET reads the
+// text and banner from the query string so results can be shared as links;
+// POST validates the form and redirects to the equivalent GET URL. The
+// format field returns the art as plain text or JSON instead of a page.
 func asciiArtHandler(w http.ResponseWriter, r *http.Request) {
+	// Check if the request method is GET or POST
+	if r.Method != "GET" && r.Method != "POST" {
+		renderMethodNotAllowed(w, "GET", "POST")
+		return
+	}
+	if len(r.URL.RawQuery) > maxQueryLength {
+		renderError(w, "Request URI too long: please shorten the text", http.StatusRequestURITooLong)
+		return
+	}
+	form, ok := parseArtForm(w, r)
+	if !ok {
+		return
+	}
+	format := r.FormValue("format")
+	if format != "" && format != formatHTML && format != formatPlain && format != formatJSON {
+		renderError(w, fmt.Sprintf("Invalid format %q: please use %s, %s or %s.", format, formatHTML, formatPlain, formatJSON), http.StatusBadRequest)
+		return
+	}
+	result, ok := renderArtForm(w, form)
+	if !ok {
+		return
+	}
+	// Optionally render the text in every banner as well
+	var comparisons []comparison
+	if r.FormValue("compare") == "1" {
+		var err error
+		if comparisons, err = compareBanners(form.Text, form.Options); err != nil {
+			msg, status := compareFailure(err)
+			renderError(w, msg, status)
+			return
+		}
+	}
+	// Plain text and JSON are for programs, so they are returned directly
+	switch format {
+	case formatPlain:
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		if comparisons != nil {
+			writeComparisons(w, comparisons)
+		} else {
+			io.WriteString(w, result)
+		}
+		return
+	case formatJSON:
+		if comparisons != nil {
+			renderJSON(w, compareResponse{Results: comparisonResults(comparisons)}, http.StatusOK)
+		} else {
+			renderJSON(w, artResult{Result: result}, http.StatusOK)
+		}
+		return
+	}
+	// Color the chosen letters in the web view; downloads ignore them
+	highlighted, ok := highlightFromForm(w, r, form)
+	if !ok {
+		return
+	}
+	// Redirect successful form submissions so refreshing does not re-submit the form,
+	// unless the text is too long to fit in a shareable URL
+	if r.Method == "POST" {
+		// Link to the banners actually used, so the result does not change on reload
+		values := maps.Clone(r.PostForm)
+		values["banner"] = form.Banners
+		query := values.Encode()
+		if len(query) <= maxQueryLength {
+			http.Redirect(w, r, "/ascii-art?"+query, http.StatusSeeOther)
+			return
+		}
+	}
+	// Render the result using the home template
+	page := homePage{Text: r.FormValue("text"), Result: result, Highlighted: highlighted, Banners: availableBanners(), BannerHeights: bannerHeights(), Selected: selectedBanner(r, form), BannerUsed: bannerUsed(r, form), Options: form.Options, Escape: r.FormValue("escape") == "1", Case: r.FormValue("case"), Limits: currentLimits(), Colors: asciiart.Colors, Color: r.FormValue("color"), Letters: r.FormValue("letters"), ColorMode: r.FormValue("colormode"), Comparisons: comparisons}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	err := currentTemplates().home.Execute(w, page)
+	if err != nil {
+		renderError(w, "Internal Server Error: Failed to render template", http.StatusInternalServerError)
+		return
+	}
+}
+
+// downloadHandler returns generated ASCII art as a file attachment in the
+// format chosen by the format field, plain text by default
+func downloadHandler(w http.ResponseWriter, r *http.Request) {
 	// Check if the request method is POST
 	if r.Method != "POST" {
-		renderError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		renderMethodNotAllowed(w, "POST")
 		return
 	}
-	// Parse form data and validate input
-	if err := r.ParseForm(); err != nil {
-		renderError(w, "Invalid form data", http.StatusBadRequest)
+	form, ok := parseArtForm(w, r)
+	if !ok {
 		return
 	}
-	text := r.FormValue("text")
-	banner := r.FormValue("banner")
-	if text == "" {
-		renderError(w, "Missing text: please provide the text for ASCII art generation.", http.StatusBadRequest)
+	format := r.FormValue("format")
+	if format == "" {
+		format = "txt"
+	}
+	export, ok := exportFormats[format]
+	if !ok {
+		renderError(w, "Invalid format: please use "+strings.Join(exportFormatNames, ", ")+".", http.StatusBadRequest)
 		return
 	}
-	if banner == "" {
-		renderError(w, "Missing banner: please select a banner for ASCII art generation.", http.StatusBadRequest)
+	result, ok := renderArtForm(w, form)
+	if !ok {
+		return
+	}
+	// Encode the whole file first so a failure can still be reported as an error page
+	var file bytes.Buffer
+	if err := export.write(&file, r, form, result); err != nil {
+		msg, status := exportFailure(err)
+		renderError(w, msg, status)
 		return
 	}
+	// Send the file so the browser saves it
+	w.Header().Set("Content-Type", export.contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.%s"`, exportFilename(form.Text), export.extension))
+	file.WriteTo(w)
+}
+
+// previewText is the sample rendered by the banner preview
+const previewText = "ABC abc 123"
 
-	// Open the banner file and generate ASCII art
-	filePath := fmt.Sprintf("ART/%s.txt", banner)
-	content, err := os.Open(filePath)
+// previewHandler renders the preview sample in the requested banner, so the
+// banner can be previewed before generating art. It is plain text or, when
+// the client accepts HTML, a <pre> fragment for the home page. Unknown
+// banners are not found.
+func previewHandler(w http.ResponseWriter, r *http.Request) {
+	// Check if the request method is GET
+	if r.Method != "GET" {
+		renderMethodNotAllowed(w, "GET")
+		return
+	}
+	banner := r.URL.Query().Get("banner")
+	if banner == "" {
+		renderError(w, "Missing banner: please select a banner to preview.", http.StatusBadRequest)
+		return
+	}
+	if !isSupportedBanner(banner) {
+		renderError(w, unsupportedBannerMessage(), http.StatusNotFound)
+		return
+	}
+	result, err := bannerPreview(banner)
 	if err != nil {
-		if os.IsNotExist(err) {
-			renderError(w, "Banner file not found", http.StatusNotFound)
-		} else {
-			renderError(w, "Internal Server Error: Failed to open banner file", http.StatusInternalServerError)
-		}
+		msg, status := renderFailure(err)
+		renderError(w, msg, status)
 		return
 	}
-	defer content.Close()
+	if strings.Contains(r.Header.Get("Accept"), "text/html") {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		io.WriteString(w, `<pre class="banner-preview">`+template.HTMLEscapeString(result)+"</pre>")
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	io.WriteString(w, result)
+}
 
-	result := generateASCIIArt(content, strings.Split(text, "\n"))
-	// Render the result using the home template
-	tmpl, err := template.ParseFiles("HTML/home.html")
+// previewCache holds the preview sample rendered in each banner, since the
+// sample never changes. It is cleared when the banners are rescanned.
+var (
+	previewCache   = make(map[string]string)
+	previewCacheMu sync.RWMutex
+)
+
+// bannerPreview returns the preview sample rendered in a banner, rendering
+// it only the first time it is requested
+func bannerPreview(banner string) (string, error) {
+	previewCacheMu.RLock()
+	result, ok := previewCache[banner]
+	previewCacheMu.RUnlock()
+	if ok {
+		return result, nil
+	}
+	result, err := renderBannerArt([]string{banner}, previewText, asciiart.DefaultOptions())
 	if err != nil {
-		renderError(w, "Internal Server Error: Failed to load template", http.StatusInternalServerError)
+		return "", err
+	}
+	previewCacheMu.Lock()
+	previewCache[banner] = result
+	previewCacheMu.Unlock()
+	return result, nil
+}
+
+// decodeHandler recovers the text from ASCII art posted in the art field,
+// matching it against the glyphs of the banner field, and returns the text
+// as plain text
+func decodeHandler(w http.ResponseWriter, r *http.Request) {
+	// Check if the request method is POST
+	if r.Method != "POST" {
+		renderMethodNotAllowed(w, "POST")
 		return
 	}
-	err = tmpl.Execute(w, map[string]string{"Result": result})
+	if !parseLimitedForm(w, r) {
+		return
+	}
+	art, banner := r.PostFormValue("art"), r.PostFormValue("banner")
+	if strings.TrimSpace(art) == "" {
+		renderError(w, "Missing art: please provide the ASCII art to decode.", http.StatusBadRequest)
+		return
+	}
+	if banner == "" {
+		renderError(w, "Missing banner: please select the banner the art was made with.", http.StatusBadRequest)
+		return
+	}
+	if !isSupportedBanner(banner) {
+		renderError(w, unsupportedBannerMessage(), http.StatusBadRequest)
+		return
+	}
+	text, err := asciiart.DecodeBanner(banner, art)
 	if err != nil {
-		renderError(w, "Internal Server Error: Failed to render template", http.StatusInternalServerError)
+		msg, status := renderFailure(err)
+		renderError(w, msg, status)
 		return
 	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	io.WriteString(w, text)
 }
 
-// generateASCIIArt creates ASCII art from user input and a banner file
-func generateASCIIArt(content *os.File, userInput []string) string {
-	// Create a map to hold the ASCII representation of each character.
-	asciiArtMap := make(map[rune][]string)
+// reverseHandler turns ASCII art posted from the reverse form back into
+// text, showing it as the result on the home page. Without a banner it tries
+// every available banner and reports the first that matches. Art that no
+// banner matches is refused with 422 and the line and column where matching
+// went furthest.
+func reverseHandler(w http.ResponseWriter, r *http.Request) {
+	// Check if the request method is POST
+	if r.Method != "POST" {
+		renderMethodNotAllowed(w, "POST")
+		return
+	}
+	if !parseLimitedForm(w, r) {
+		return
+	}
+	art := r.PostFormValue("art")
+	if strings.TrimSpace(art) == "" {
+		renderError(w, "Missing art: please paste the ASCII art to reverse.", http.StatusBadRequest)
+		return
+	}
+	banners := availableBanners()
+	if banner := r.PostFormValue("banner"); banner != "" {
+		if !isSupportedBanner(banner) {
+			renderError(w, unsupportedBannerMessage(), http.StatusBadRequest)
+			return
+		}
+		banners = []string{banner}
+	}
+	// Keep the failure that got furthest into the art to report
+	var closest *asciiart.DecodeError
+	for _, banner := range banners {
+		text, err := asciiart.DecodeBanner(banner, art)
+		var failed *asciiart.DecodeError
+		if errors.As(err, &failed) {
+			if closest == nil || failed.Line > closest.Line || failed.Line == closest.Line && failed.Column > closest.Column {
+				closest = failed
+			}
+			continue
+		}
+		if err != nil {
+			msg, status := renderFailure(err)
+			renderError(w, msg, status)
+			return
+		}
+		w.Header().Set("X-Banner-Used", banner)
+		page := homePage{Result: text, Banners: availableBanners(), BannerHeights: bannerHeights(), Selected: defaultBanner, Options: defaultFormOptions(), Limits: currentLimits(), Colors: asciiart.Colors, ReverseArt: art, ReversedBanner: banner}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if err := currentTemplates().home.Execute(w, page); err != nil {
+			renderError(w, "Internal Server Error: Failed to render template", http.StatusInternalServerError)
+		}
+		return
+	}
+	if closest == nil {
+		renderError(w, "No banners are available to reverse the art with.", http.StatusInternalServerError)
+		return
+	}
+	renderError(w, closest.Error(), http.StatusUnprocessableEntity)
+}
 
-	// Assume each character's art is 8 lines high.
-	const height = 8
+// artForm is the validated input of the ASCII art form
+type artForm struct {
+	Text    string
+	Banners []string
+	Options asciiart.Options
+}
 
-	// Read the banner font characters into the map.
-	scanner := bufio.NewScanner(content)
-	for i := 32; i <= 126; i++ { // For all printable ASCII characters
-		asciiArt := make([]string, height)
-		for j := range asciiArt {
-			if !scanner.Scan() {
-				log.Fatal("Error reading banner font file")
-			}
-			asciiArt[j] = scanner.Text()
+// parseLimitedForm parses a URL-encoded or multipart form from a body capped
+// at maxBodyBytes. On failure it renders the error page and reports false.
+func parseLimitedForm(w http.ResponseWriter, r *http.Request) bool {
+	r.Body = http.MaxBytesReader(w, r.Body, maxBodyBytes)
+	parse := r.ParseForm
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == "multipart/form-data" {
+		// Uploads are kept in memory; the body is already capped at maxBodyBytes
+		parse = func() error { return r.ParseMultipartForm(maxBodyBytes) }
+	}
+	if err := parse(); err != nil {
+		// A read deadline firing while the body is still arriving is a timeout, not bad input
+		var netErr net.Error
+		if errors.As(err, &netErr) && netErr.Timeout() {
+			renderError(w, "Request timed out while reading form data", http.StatusRequestTimeout)
+			return false
 		}
-		asciiArtMap[rune(i)] = asciiArt
-		// Skip the blank line after each character's art
-		if !scanner.Scan() {
-			log.Fatal("Error reading banner font file")
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			renderError(w, bodyTooLargeMessage(), http.StatusRequestEntityTooLarge)
+			return false
 		}
+		renderError(w, "Invalid form data", http.StatusBadRequest)
+		return false
 	}
+	return true
+}
 
-	// Build the ASCII art for the user's input
-	var result strings.Builder
-	for _, line := range userInput {
-		for i := 0; i < height; i++ {
-			for _, char := range line {
-				if art, ok := asciiArtMap[char]; ok {
-					result.WriteString(art[i])
-				} else {
-					result.WriteString(" ") // Handle unknown characters
-				}
+// parseArtForm reads and validates the submitted form. On failure it
+// renders the error page and reports false.
+func parseArtForm(w http.ResponseWriter, r *http.Request) (artForm, bool) {
+	// Parse form data and validate input
+	if !parseLimitedForm(w, r) {
+		return artForm{}, false
+	}
+	// An uploaded text file takes the place of the textarea
+	if r.MultipartForm != nil {
+		if files := r.MultipartForm.File["file"]; len(files) > 0 && files[0].Filename != "" {
+			text, ok := uploadedText(w, files[0])
+			if !ok {
+				return artForm{}, false
 			}
-			result.WriteString("\n")
+			// Store the text with the other fields so the redirect and the result page use it
+			r.Form.Set("text", text)
+			r.PostForm.Set("text", text)
+		}
+	}
+	// Browsers submit textarea line breaks as \r\n; count and validate each as one character
+	text := asciiart.NormalizeLineEndings(r.FormValue("text"))
+	// One banner applies to every line; repeating the field picks a banner per line
+	banners := r.Form["banner"]
+	// Optionally turn literal \n sequences into line breaks
+	if r.FormValue("escape") == "1" {
+		unescaped, err := asciiart.Unescape(text)
+		if err != nil {
+			renderError(w, err.Error(), http.StatusBadRequest)
+			return artForm{}, false
+		}
+		text = unescaped
+	}
+	// Change the case before validation so errors refer to the rendered text
+	text, err := asciiart.ChangeCase(text, r.FormValue("case"))
+	if err != nil {
+		renderError(w, err.Error(), http.StatusBadRequest)
+		return artForm{}, false
+	}
+	if text == "" {
+		renderError(w, "Missing text: please provide the text for ASCII art generation.", http.StatusBadRequest)
+		return artForm{}, false
+	}
+	if textTooLong(text) {
+		renderError(w, textTooLongMessage(), http.StatusBadRequest)
+		return artForm{}, false
+	}
+	if msg := checkLineLimits(text); msg != "" {
+		renderError(w, msg, http.StatusBadRequest)
+		return artForm{}, false
+	}
+	if len(banners) == 0 || slices.Contains(banners, "") {
+		renderError(w, "Missing banner: please select a banner for ASCII art generation.", http.StatusBadRequest)
+		return artForm{}, false
+	}
+	// Pick a banner for each field asking for a random one
+	banners = slices.Clone(banners)
+	for i, banner := range banners {
+		if banner == randomBanner {
+			banners[i] = pickRandomBanner()
 		}
-		result.WriteString("\n") // Add an additional newline to separate the lines
+	}
+	for _, banner := range banners {
+		if !isSupportedBanner(banner) {
+			renderError(w, unsupportedBannerMessage(), http.StatusBadRequest)
+			return artForm{}, false
+		}
+	}
+	if lines := len(asciiart.SplitLines(text)); len(banners) > 1 && len(banners) != lines {
+		renderError(w, fmt.Sprintf("Banner count mismatch: please select one banner, or one for each of the %d lines.", lines), http.StatusBadRequest)
+		return artForm{}, false
+	}
+	opts, err := optionsFromForm(r)
+	if err != nil {
+		renderError(w, err.Error(), http.StatusBadRequest)
+		return artForm{}, false
 	}
 
-	return result.String()
+	// Report the banners actually used, since they may have been picked at random
+	w.Header().Set("X-Banner-Used", strings.Join(banners, ", "))
+	return artForm{Text: text, Banners: banners, Options: opts}, true
 }
 
-// renderError displays an error message to the user
-func renderError(w http.ResponseWriter, errMsg string, statusCode int) {
-	// Set the HTTP status code
-	w.WriteHeader(statusCode)
-	// Parse and execute the error template
-	tmpl, err := template.ParseFiles("HTML/error.html")
+// selectedBanner is the banner shown as chosen in the form: the one actually
+// used when it was picked at random, or else the one submitted
+func selectedBanner(r *http.Request, form artForm) string {
+	if slices.Contains(r.Form["banner"], randomBanner) && len(form.Banners) > 0 {
+		return form.Banners[0]
+	}
+	return r.FormValue("banner")
+}
+
+// bannerUsed names the banners the form was rendered in when any of them
+// was picked at random, and is empty otherwise
+func bannerUsed(r *http.Request, form artForm) string {
+	if !slices.Contains(r.Form["banner"], randomBanner) {
+		return ""
+	}
+	return strings.Join(form.Banners, ", ")
+}
+
+// uploadedText reads an uploaded text file. On failure it renders the error
+// page and reports false.
+func uploadedText(w http.ResponseWriter, header *multipart.FileHeader) (string, bool) {
+	if header.Size > maxBodyBytes {
+		renderError(w, fmt.Sprintf("File too large: uploads are limited to %d bytes.", maxBodyBytes), http.StatusRequestEntityTooLarge)
+		return "", false
+	}
+	file, err := header.Open()
 	if err != nil {
-		// If the error template fails to load, log the error and send a basic error message
-		log.Printf("Error loading error template: %v", err)
-		http.Error(w, "500 Internal Server Error: Failed to load error template", http.StatusInternalServerError)
+		log.Printf("Error opening upload: %v", err)
+		renderError(w, "Internal Server Error: Failed to read the uploaded file", http.StatusInternalServerError)
+		return "", false
+	}
+	defer file.Close()
+	data, err := io.ReadAll(file)
+	if err != nil {
+		log.Printf("Error reading upload: %v", err)
+		renderError(w, "Internal Server Error: Failed to read the uploaded file", http.StatusInternalServerError)
+		return "", false
+	}
+	return string(data), true
+}
+
+// renderArtForm generates the ASCII art for a validated form. On failure it
+// renders the error page and reports false.
+func renderArtForm(w http.ResponseWriter, form artForm) (string, bool) {
+	// Look up the banner font and generate ASCII art
+	result, err := renderBannerArt(form.Banners, form.Text, form.Options)
+	if err != nil {
+		msg, status := renderFailure(err)
+		renderError(w, msg, status)
+		return "", false
+	}
+
+	return result, true
+}
+
+// highlightFromForm renders the art as HTML with the letters field colored
+// in the color field, or every letter colored for the rainbow color. It
+// returns empty HTML when there is nothing to color and on failure renders
+// the error page and reports false.
+func highlightFromForm(w http.ResponseWriter, r *http.Request, form artForm) (template.HTML, bool) {
+	letters, color := r.FormValue("letters"), r.FormValue("color")
+	if letters == "" && color != asciiart.ColorRainbow {
+		return "", true
+	}
+	highlighted, err := asciiart.RenderHighlighted(form.Banners, asciiart.SplitLines(form.Text), form.Options, letters, color)
+	if err != nil {
+		msg, status := renderFailure(err)
+		renderError(w, msg, status)
+		return "", false
+	}
+	return highlighted, true
+}
+
+// renderFailure maps an error from renderBannerArt to the message and status code for the client
+func renderFailure(err error) (string, int) {
+	var unsupported *asciiart.UnsupportedCharsError
+	var invalid *asciiart.InvalidTextError
+	var tooWide *asciiart.WidthExceededError
+	var badColor *asciiart.InvalidColorError
+	var undecodable *asciiart.DecodeError
+	switch {
+	case errors.As(err, &unsupported), errors.As(err, &invalid), errors.As(err, &tooWide), errors.As(err, &badColor), errors.As(err, &undecodable), errors.Is(err, asciiart.ErrTabsNotAllowed):
+		return err.Error(), http.StatusBadRequest
+	case errors.Is(err, os.ErrNotExist):
+		return "Banner file not found", http.StatusNotFound
+	default:
+		log.Printf("Error loading banner: %v", err)
+		return "Internal Server Error: Failed to load banner file", http.StatusInternalServerError
+	}
+}
+
+// defaultFormWidth is the width the web form centers and right-aligns art within
+const defaultFormWidth = 120
+
+// defaultFormOptions returns the options the web form starts with
+func defaultFormOptions() asciiart.Options {
+	opts := asciiart.DefaultOptions()
+	opts.Width = defaultFormWidth
+	return opts
+}
+
+// optionsFromForm reads the rendering options from the submitted form
+func optionsFromForm(r *http.Request) (asciiart.Options, error) {
+	opts := asciiart.Options{Unknown: r.FormValue("unknown"), Align: r.FormValue("align"), TabWidth: tabWidth, Border: r.FormValue("border"), Trim: r.FormValue("trim") == "1", Transform: r.FormValue("transform")}
+	if value := r.FormValue("tabwidth"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return opts, fmt.Errorf("Invalid tabwidth %q: please use a whole number of spaces.", value)
+		}
+		opts.TabWidth = n
+	}
+	opts.Width = defaultFormWidth
+	if value := r.FormValue("width"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return opts, fmt.Errorf("Invalid width %q: please use a whole number of columns.", value)
+		}
+		opts.Width = n
+	}
+	if value := r.FormValue("spacing"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return opts, fmt.Errorf("Invalid spacing %q: please use a whole number of columns.", value)
+		}
+		opts.Spacing = n
+	}
+	if value := r.FormValue("linespacing"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return opts, fmt.Errorf("Invalid line spacing %q: please use a whole number of rows.", value)
+		}
+		opts.LineSpacing = n
+	}
+	if value := r.FormValue("wrap"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return opts, fmt.Errorf("Invalid wrap %q: please use a whole number of columns.", value)
+		}
+		opts.Wrap = n
+	}
+	limitArtWidth(&opts)
+	return opts, opts.Validate()
+}
+
+// limitArtWidth makes lines wrap at the configured maximum art width, and
+// keeps the requested wrap and alignment widths within it
+func limitArtWidth(opts *asciiart.Options) {
+	if maxArtWidth <= 0 {
 		return
 	}
-	// Handle any errors that occur during template parsing or execution
-	tmpl.Execute(w, map[string]string{"ErrorMessage": errMsg})
+	if opts.Wrap <= 0 || opts.Wrap > maxArtWidth {
+		opts.Wrap = maxArtWidth
+	}
+	opts.Width = min(opts.Width, maxArtWidth)
+}
+
+// textTooLong reports whether the text exceeds the maximum input length
+func textTooLong(text string) bool {
+	return utf8.RuneCountInString(text) > maxTextLength
+}
+
+// textTooLongMessage explains the input length limit
+func textTooLongMessage() string {
+	return fmt.Sprintf("Text too long: please provide at most %d characters.", maxTextLength)
+}
+
+// checkLineLimits returns a message describing the limit the text exceeds,
+// or an empty string when it has an acceptable number and length of lines
+func checkLineLimits(text string) string {
+	lines := asciiart.SplitLines(text)
+	if len(lines) > maxLines {
+		return fmt.Sprintf("Too many lines: please provide at most %d lines of at most %d characters each.", maxLines, maxLineLength)
+	}
+	for _, line := range lines {
+		if utf8.RuneCountInString(line) > maxLineLength {
+			return fmt.Sprintf("Line too long: please provide at most %d lines of at most %d characters each.", maxLines, maxLineLength)
+		}
+	}
+	return ""
+}
+
+// bodyTooLargeMessage explains the request body size limit
+func bodyTooLargeMessage() string {
+	return fmt.Sprintf("Request too large: the request body is limited to %d bytes.", maxBodyBytes)
+}
+
+// renderBannerArt generates the ASCII art for text using the named banner,
+// or with one banner per line when several are given. Unless the options
+// allow unsupported characters, the text is validated first so the error
+// can point at each offending character.
+func renderBannerArt(banners []string, text string, opts asciiart.Options) (string, error) {
+	if opts.Unknown == asciiart.UnknownError {
+		if err := asciiart.ValidateText(text); err != nil {
+			return "", err
+		}
+	}
+	lines := asciiart.SplitLines(text)
+	if len(banners) == 1 {
+		return asciiart.RenderWithOptions(banners[0], lines, opts)
+	}
+	return asciiart.RenderPerLine(banners, lines, opts)
+}
+
+// rescanBanners re-reads and parses every banner in the banner directory,
+// replacing the cached fonts so files changed on disk are picked up, and
+// refreshes the list of supported banners. Banners that fail to load are
+// logged and left out of the list, and their names are returned.
+func rescanBanners() ([]string, error) {
+	banners, problems, err := asciiart.ReadBanners()
+	if err != nil {
+		return nil, err
+	}
+	invalid := make([]string, 0, len(problems))
+	for banner := range problems {
+		invalid = append(invalid, banner)
+	}
+	slices.Sort(invalid)
+	for _, banner := range invalid {
+		log.Printf("Invalid banner: %v", problems[banner])
+	}
+	asciiart.UseBanners(banners)
+	setSupportedBanners(banners.Names())
+	return invalid, nil
+}
+
+// setSupportedBanners replaces the banner allow-list and clears the
+// previews rendered from the old banners
+func setSupportedBanners(banners []string) {
+	supportedBannersMu.Lock()
+	supportedBanners = banners
+	supportedBannersMu.Unlock()
+	previewCacheMu.Lock()
+	previewCache = make(map[string]string)
+	previewCacheMu.Unlock()
+}
+
+// availableBanners returns a copy of the supported banner names, which
+// asciiart lists in sorted order
+func availableBanners() []string {
+	supportedBannersMu.RLock()
+	defer supportedBannersMu.RUnlock()
+	return slices.Clone(supportedBanners)
+}
+
+// bannerHeights maps each available banner to the height of its glyphs
+func bannerHeights() map[string]int {
+	heights := make(map[string]int)
+	for _, banner := range availableBanners() {
+		if info, err := asciiart.BannerDetails(banner); err == nil {
+			heights[banner] = info.Height
+		}
+	}
+	return heights
+}
+
+// unsupportedBannerMessage explains which banners may be requested
+func unsupportedBannerMessage() string {
+	return "Unsupported banner: please select one of " + strings.Join(availableBanners(), ", ") + "."
+}
+
+// isSupportedBanner reports whether a banner name is in the allow-list
+func isSupportedBanner(banner string) bool {
+	supportedBannersMu.RLock()
+	defer supportedBannersMu.RUnlock()
+	return slices.Contains(supportedBanners, banner)
+}
+
+// renderMethodNotAllowed displays a 405 error listing the methods the route accepts
+func renderMethodNotAllowed(w http.ResponseWriter, allowed ...string) {
+	w.Header().Set("Allow", strings.Join(allowed, ", "))
+	renderError(w, "Method not allowed", http.StatusMethodNotAllowed)
+}
+
+// renderError displays an error message to the user. The page is rendered
+// into a buffer first so the status code is written exactly once.
+func renderError(w http.ResponseWriter, errMsg string, statusCode int) {
+	var body bytes.Buffer
+	// Fall back to a plain text error if the template fails
+	if err := currentTemplates().errorPage.Execute(&body, map[string]string{"ErrorMessage": errMsg}); err != nil {
+		log.Printf("Error rendering error template: %v", err)
+		http.Error(w, errMsg, statusCode)
+		return
+	}
+	// Set the content type and HTTP status code, then write the page
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(statusCode)
+	w.Write(body.Bytes())
 }
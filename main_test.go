@@ -0,0 +1,483 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"io/fs"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"ASCII/asciiart"
+)
+
+// TestMain loads the embedded banners and templates as main does
+func TestMain(m *testing.M) {
+	bannerDir, err := fs.Sub(embeddedAssets, "ART")
+	if err != nil {
+		log.Fatal(err)
+	}
+	asciiart.UseBannerFS(bannerDir, asciiart.SourceBuiltin)
+	if err := loadTemplates(); err != nil {
+		log.Fatal(err)
+	}
+	if _, err := rescanBanners(); err != nil {
+		log.Fatal(err)
+	}
+	os.Exit(m.Run())
+}
+
+// useBanners serves the banners in fsys for the rest of the test, listing
+// them all even if they fail to load
+func useBanners(t *testing.T, fsys fs.FS) {
+	t.Helper()
+	asciiart.UseBannerFS(fsys, asciiart.SourceCustom)
+	banners, err := asciiart.Banners()
+	if err != nil {
+		t.Fatal(err)
+	}
+	setSupportedBanners(banners)
+	t.Cleanup(func() {
+		bannerDir, _ := fs.Sub(embeddedAssets, "ART")
+		asciiart.UseBannerFS(bannerDir, asciiart.SourceBuiltin)
+		if _, err := rescanBanners(); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+// serve sends a request through the router and returns the recorded response
+func serve(method, target, form string) *httptest.ResponseRecorder {
+	var req *http.Request
+	if form != "" {
+		req = httptest.NewRequest(method, target, strings.NewReader(form))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	} else {
+		req = httptest.NewRequest(method, target, nil)
+	}
+	rec := httptest.NewRecorder()
+	Serverouter(rec, req)
+	return rec
+}
+
+func TestHandlers(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		target     string
+		form       string
+		wantStatus int
+		wantBody   string
+	}{
+		{name: "home page", method: "GET", target: "/", wantStatus: http.StatusOK, wantBody: `<form`},
+		{name: "art", method: "GET", target: "/ascii-art?text=Hi&banner=standard", wantStatus: http.StatusOK, wantBody: " _    _   _  \n| |  | | (_) \n"},
+		{name: "art redirects to a shareable link", method: "POST", target: "/ascii-art", form: "text=Hi&banner=standard", wantStatus: http.StatusSeeOther},
+		{name: "missing text", method: "POST", target: "/ascii-art", form: "banner=standard", wantStatus: http.StatusBadRequest, wantBody: "Missing text"},
+		{name: "unknown banner", method: "POST", target: "/ascii-art", form: "text=Hi&banner=nope", wantStatus: http.StatusBadRequest, wantBody: "Unsupported banner"},
+		{name: "unsupported characters", method: "POST", target: "/ascii-art", form: "text=%E2%82%AC&banner=standard", wantStatus: http.StatusBadRequest, wantBody: "unsupported character &#39;€&#39; at position 1"},
+		{name: "unknown path", method: "GET", target: "/nope", wantStatus: http.StatusNotFound, wantBody: "Page not found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve(tt.method, tt.target, tt.form)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("%s %s returned %d, want %d\n%s", tt.method, tt.target, rec.Code, tt.wantStatus, rec.Body)
+			}
+			if !strings.Contains(rec.Body.String(), tt.wantBody) {
+				t.Errorf("%s %s body does not contain %q:\n%s", tt.method, tt.target, tt.wantBody, rec.Body)
+			}
+		})
+	}
+}
+
+func TestMethodNotAllowed(t *testing.T) {
+	tests := []struct {
+		method    string
+		target    string
+		wantAllow string
+	}{
+		{method: "POST", target: "/", wantAllow: "GET, HEAD"},
+		{method: "PUT", target: "/ascii-art", wantAllow: "GET, POST"},
+		{method: "DELETE", target: "/style.css", wantAllow: "GET, HEAD"},
+		{method: "GET", target: "/download", wantAllow: "POST"},
+	}
+	for _, tt := range tests {
+		rec := serve(tt.method, tt.target, "")
+		if rec.Code != http.StatusMethodNotAllowed {
+			t.Errorf("%s %s returned %d, want %d", tt.method, tt.target, rec.Code, http.StatusMethodNotAllowed)
+			continue
+		}
+		if got := rec.Header().Get("Allow"); got != tt.wantAllow {
+			t.Errorf("%s %s: Allow = %q, want %q", tt.method, tt.target, got, tt.wantAllow)
+		}
+	}
+}
+
+func TestNotFound(t *testing.T) {
+	// Unknown paths are not found whatever the method
+	for _, method := range []string{"GET", "POST", "DELETE"} {
+		for _, target := range []string{"/nope", "/doesnotexist", "/ascii-art/", "/api/nope"} {
+			if rec := serve(method, target, ""); rec.Code != http.StatusNotFound {
+				t.Errorf("%s %s returned %d, want %d", method, target, rec.Code, http.StatusNotFound)
+			}
+		}
+	}
+}
+
+func TestRandomBannerRedirect(t *testing.T) {
+	rec := serve("POST", "/ascii-art", "text=hi&banner=random")
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("POST /ascii-art returned %d, want %d", rec.Code, http.StatusSeeOther)
+	}
+	used := rec.Header().Get("X-Banner-Used")
+	if !isSupportedBanner(used) {
+		t.Fatalf("X-Banner-Used = %q, want a supported banner", used)
+	}
+	if want := "/ascii-art?banner=" + used + "&text=hi"; rec.Header().Get("Location") != want {
+		t.Errorf("Location = %q, want %q", rec.Header().Get("Location"), want)
+	}
+}
+
+// lastPicker is a bannerPicker that always picks the last banner offered
+// and remembers the banners it was offered
+type lastPicker struct {
+	offered []string
+}
+
+func (p *lastPicker) Pick(banners []string) string {
+	p.offered = banners
+	return banners[len(banners)-1]
+}
+
+// usePicker sets the picker for banner=random requests for the rest of the test
+func usePicker(t *testing.T, p bannerPicker) {
+	t.Helper()
+	previous := picker
+	picker = p
+	t.Cleanup(func() { picker = previous })
+}
+
+func TestRandomBannerPicker(t *testing.T) {
+	standard, err := fs.ReadFile(embeddedAssets, "ART/standard.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	shadow, err := fs.ReadFile(embeddedAssets, "ART/shadow.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// zzz sorts last, so it would be picked if it were offered
+	useBanners(t, fstest.MapFS{
+		"shadow.txt":   {Data: shadow},
+		"standard.txt": {Data: standard},
+		"zzz.txt":      {Data: []byte("not a banner\n")},
+	})
+	stub := &lastPicker{}
+	usePicker(t, stub)
+
+	rec := serve("POST", "/ascii-art", "text=hi&banner=random")
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("POST /ascii-art returned %d, want %d", rec.Code, http.StatusSeeOther)
+	}
+	if got := strings.Join(stub.offered, ","); got != "shadow,standard" {
+		t.Errorf("picker was offered %q, want the banners that load", got)
+	}
+	if got := rec.Header().Get("X-Banner-Used"); got != "standard" {
+		t.Errorf("X-Banner-Used = %q, want standard", got)
+	}
+	if got, want := rec.Header().Get("Location"), "/ascii-art?banner=standard&text=hi"; got != want {
+		t.Errorf("Location = %q, want %q", got, want)
+	}
+
+	rec = serveJSON("/api/ascii-art", `{"text":"hi","banner":"random"}`)
+	if rec.Code != http.StatusOK || rec.Header().Get("X-Banner-Used") != "standard" {
+		t.Fatalf("API returned %d with X-Banner-Used %q, want 200 with standard", rec.Code, rec.Header().Get("X-Banner-Used"))
+	}
+	var response apiResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatal(err)
+	}
+	want, err := renderBannerArt([]string{"standard"}, "hi", asciiart.DefaultOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response.Banner != "standard" || response.Art != want {
+		t.Errorf("API response = %+v, want the art in standard", response)
+	}
+}
+
+func TestTextLimits(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{name: "too many characters", text: strings.Repeat("a", maxTextLength+1), want: "Text too long"},
+		{name: "too many lines", text: strings.Repeat("a\n", maxLines), want: "Too many lines"},
+		{name: "line too long", text: strings.Repeat("a", maxLineLength+1), want: "Line too long"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve("POST", "/ascii-art", "banner=standard&text="+tt.text)
+			if rec.Code != http.StatusBadRequest {
+				t.Fatalf("POST /ascii-art returned %d, want %d", rec.Code, http.StatusBadRequest)
+			}
+			if !strings.Contains(rec.Body.String(), tt.want) {
+				t.Errorf("body does not contain %q:\n%s", tt.want, rec.Body)
+			}
+		})
+	}
+}
+
+func TestFormBodyLimit(t *testing.T) {
+	for _, target := range []string{"/ascii-art", "/decode", "/ascii-art/reverse"} {
+		rec := serve("POST", target, "art="+strings.Repeat("a", int(maxBodyBytes)))
+		if rec.Code != http.StatusRequestEntityTooLarge {
+			t.Errorf("POST %s with an oversized body returned %d, want %d", target, rec.Code, http.StatusRequestEntityTooLarge)
+		}
+	}
+}
+
+func TestCorruptBanner(t *testing.T) {
+	standard, err := fs.ReadFile(embeddedAssets, "ART/standard.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	useBanners(t, fstest.MapFS{
+		"standard.txt": &fstest.MapFile{Data: standard},
+		"broken.txt":   &fstest.MapFile{Data: standard[:len(standard)/2]},
+	})
+	rec := serve("POST", "/ascii-art", "text=Hi&banner=broken")
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("POST /ascii-art with a corrupt banner returned %d, want %d", rec.Code, http.StatusInternalServerError)
+	}
+	if want := "Failed to load banner file"; !strings.Contains(rec.Body.String(), want) {
+		t.Errorf("body does not contain %q:\n%s", want, rec.Body)
+	}
+	// The server keeps serving other banners
+	if rec := serve("POST", "/ascii-art", "text=Hi&banner=standard&format=plain"); rec.Code != http.StatusOK {
+		t.Errorf("POST /ascii-art after the failure returned %d, want %d", rec.Code, http.StatusOK)
+	}
+}
+
+// openRecorder is a filesystem that records the names of the files opened
+type openRecorder struct {
+	fs.FS
+	opened []string
+}
+
+func (o *openRecorder) Open(name string) (fs.File, error) {
+	o.opened = append(o.opened, name)
+	return o.FS.Open(name)
+}
+
+func TestBannerPathTraversal(t *testing.T) {
+	bannerDir, err := fs.Sub(embeddedAssets, "ART")
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorder := &openRecorder{FS: bannerDir}
+	useBanners(t, recorder)
+	recorder.opened = nil
+	for _, banner := range []string{"../main", "../HTML/home", `..\main`, "/etc/passwd"} {
+		rec := serve("POST", "/ascii-art", "text=Hi&banner="+url.QueryEscape(banner))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("banner %q returned %d, want %d", banner, rec.Code, http.StatusBadRequest)
+		}
+	}
+	if len(recorder.opened) > 0 {
+		t.Errorf("opened %q, want no files opened", recorder.opened)
+	}
+}
+
+func TestCRLFText(t *testing.T) {
+	rec := serve("POST", "/ascii-art", "banner=standard&format=plain&text=ab%0D%0Acd")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("POST /ascii-art returned %d, want %d\n%s", rec.Code, http.StatusOK, rec.Body)
+	}
+	want, err := renderBannerArt([]string{"standard"}, "ab\ncd", asciiart.DefaultOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec.Body.String() != want {
+		t.Errorf("art for \"ab\\r\\ncd\" =\n%s\nwant\n%s", rec.Body, want)
+	}
+	if strings.Contains(rec.Body.String(), "\r") {
+		t.Error("art contains a carriage return")
+	}
+	if rows := strings.Count(rec.Body.String(), "\n"); rows != 16 {
+		t.Errorf("art has %d rows, want two lines of 8", rows)
+	}
+}
+
+// serveJSON posts a JSON body to the API and returns the recorded response
+func serveJSON(target, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("POST", target, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	Serverouter(rec, req)
+	return rec
+}
+
+func TestCRLFMatchesLF(t *testing.T) {
+	for _, form := range []string{"Hello%0D%0AWorld", "Hello%0DWorld"} {
+		got := serve("POST", "/ascii-art", "banner=standard&format=plain&text="+form)
+		want := serve("POST", "/ascii-art", "banner=standard&format=plain&text=Hello%0AWorld")
+		if got.Code != http.StatusOK || got.Body.String() != want.Body.String() {
+			t.Errorf("form text %s returned %d:\n%s\nwant\n%s", form, got.Code, got.Body, want.Body)
+		}
+	}
+	got := serveJSON("/api/ascii-art", `{"text":"Hello\r\nWorld","banner":"standard"}`)
+	want := serveJSON("/api/ascii-art", `{"text":"Hello\nWorld","banner":"standard"}`)
+	if got.Code != http.StatusOK || got.Body.String() != want.Body.String() {
+		t.Errorf("API text with CRLF returned %d:\n%s\nwant\n%s", got.Code, got.Body, want.Body)
+	}
+}
+
+func TestUnknownOption(t *testing.T) {
+	tests := []struct {
+		unknown    string
+		wantStatus int
+	}{
+		{unknown: "", wantStatus: http.StatusBadRequest},
+		{unknown: "error", wantStatus: http.StatusBadRequest},
+		{unknown: "skip", wantStatus: http.StatusOK},
+		{unknown: "space", wantStatus: http.StatusOK},
+		{unknown: "ignore", wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		form := serve("POST", "/ascii-art", "banner=standard&format=plain&text=a%E2%82%ACb&unknown="+tt.unknown)
+		if form.Code != tt.wantStatus {
+			t.Errorf("form with unknown=%q returned %d, want %d", tt.unknown, form.Code, tt.wantStatus)
+		}
+		api := serveJSON("/api/ascii-art", `{"text":"a€b","banner":"standard","unknown":"`+tt.unknown+`"}`)
+		if api.Code != tt.wantStatus {
+			t.Errorf("API with unknown=%q returned %d, want %d", tt.unknown, api.Code, tt.wantStatus)
+		}
+	}
+}
+
+func TestInvalidTextAPI(t *testing.T) {
+	rec := serveJSON("/api/ascii-art", `{"text":"hé\u001f","banner":"standard"}`)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("API returned %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+	var body struct {
+		Characters []struct {
+			Char     string `json:"char"`
+			Position int    `json:"position"`
+		} `json:"characters"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if len(body.Characters) != 2 || body.Characters[0].Char != "é" || body.Characters[0].Position != 2 || body.Characters[1].Char != "\x1f" || body.Characters[1].Position != 3 {
+		t.Errorf("characters = %+v, want 'é' at 2 and '\\x1f' at 3", body.Characters)
+	}
+}
+
+// countingReader is an endless request body of 'a's that counts the bytes read
+type countingReader struct {
+	read int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = 'a'
+	}
+	c.read += int64(len(p))
+	return len(p), nil
+}
+
+func TestBodySizeLimit(t *testing.T) {
+	defer func(limit int64) { maxBodyBytes = limit }(maxBodyBytes)
+	maxBodyBytes = 100
+	prefix := "banner=standard&format=plain&text="
+	// Each + decodes to a space, so the text stays within the other limits
+	under := prefix + strings.Repeat("+", int(maxBodyBytes)-len(prefix))
+	if rec := serve("POST", "/ascii-art", under); rec.Code != http.StatusOK {
+		t.Errorf("body of %d bytes returned %d, want %d", len(under), rec.Code, http.StatusOK)
+	}
+	over := under + "+"
+	if rec := serve("POST", "/ascii-art", over); rec.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("body of %d bytes returned %d, want %d", len(over), rec.Code, http.StatusRequestEntityTooLarge)
+	}
+
+	// An endless body is cut off soon after the limit
+	body := &countingReader{}
+	req := httptest.NewRequest("POST", "/ascii-art", io.MultiReader(strings.NewReader(prefix), body))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec := httptest.NewRecorder()
+	Serverouter(rec, req)
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("endless body returned %d, want %d", rec.Code, http.StatusRequestEntityTooLarge)
+	}
+	if body.read > 64<<10 {
+		t.Errorf("handler read %d bytes of an endless body, want it to stop near the %d byte limit", body.read, maxBodyBytes)
+	}
+}
+
+func TestTabWidthOption(t *testing.T) {
+	if rec := serve("POST", "/ascii-art", "banner=standard&format=plain&text=a%09b"); rec.Code != http.StatusOK {
+		t.Errorf("text with a tab returned %d, want %d\n%s", rec.Code, http.StatusOK, rec.Body)
+	}
+	rec := serve("POST", "/ascii-art", "banner=standard&format=plain&tabwidth=0&text=a%09b")
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "Tabs are not allowed") {
+		t.Errorf("text with a tab and tabwidth=0 returned %d, want %d rejecting tabs\n%s", rec.Code, http.StatusBadRequest, rec.Body)
+	}
+}
+
+func TestEscapeOption(t *testing.T) {
+	got := serve("POST", "/ascii-art", "banner=standard&format=plain&escape=1&text="+url.QueryEscape(`a\n\nb\n`))
+	want := serve("POST", "/ascii-art", "banner=standard&format=plain&text="+url.QueryEscape("a\n\nb\n"))
+	if got.Code != http.StatusOK || got.Body.String() != want.Body.String() {
+		t.Errorf("escaped text returned %d:\n%s\nwant\n%s", got.Code, got.Body, want.Body)
+	}
+	if rec := serve("POST", "/ascii-art", "banner=standard&escape=1&text="+url.QueryEscape(`a\`)); rec.Code != http.StatusBadRequest {
+		t.Errorf("trailing backslash returned %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+	api := serveJSON("/api/ascii-art", `{"text":"a\\n\\nb\\n","banner":"standard","escape":true}`)
+	if api.Code != http.StatusOK {
+		t.Errorf("API with escapes returned %d, want %d\n%s", api.Code, http.StatusOK, api.Body)
+	}
+}
+
+func TestEdgeSpaces(t *testing.T) {
+	tests := []struct {
+		name string
+		form string
+		want string // text whose art the form should render, line by line
+	}{
+		{name: "without wrap", form: "text=" + url.QueryEscape("  hi  "), want: "  hi  "},
+		// The wrap breaks at one of the two spaces between the words
+		{name: "with wrap", form: "wrap=50&text=" + url.QueryEscape("  hi  there  "), want: "  hi \nthere  "},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve("POST", "/ascii-art", "banner=standard&format=plain&"+tt.form)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("POST /ascii-art returned %d, want %d\n%s", rec.Code, http.StatusOK, rec.Body)
+			}
+			want, err := renderBannerArt([]string{"standard"}, tt.want, asciiart.DefaultOptions())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if rec.Body.String() != want {
+				t.Errorf("art =\n%s\nwant the art for %q\n%s", rec.Body, tt.want, want)
+			}
+			// The art starts and ends with two space glyphs of 6 columns
+			rows := strings.Split(strings.TrimSuffix(rec.Body.String(), "\n"), "\n")
+			if !strings.HasPrefix(rows[0], strings.Repeat(" ", 12)) {
+				t.Errorf("first row %q does not start with two spaces", rows[0])
+			}
+			if last := rows[len(rows)-1]; !strings.HasSuffix(last, strings.Repeat(" ", 12)) {
+				t.Errorf("last row %q does not end with two spaces", last)
+			}
+		})
+	}
+}
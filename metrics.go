@@ -0,0 +1,48 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Request metrics, recorded by loggingMiddleware and served at /metrics.
+var (
+	requestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "ascii_art_http_requests_total",
+		Help: "Number of HTTP requests by path and status code.",
+	}, []string{"path", "status"})
+	requestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "ascii_art_http_request_duration_seconds",
+		Help:    "Time taken to handle HTTP requests by path.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"path"})
+)
+
+// metricsHandler serves the metrics in the Prometheus text format
+var metricsHandler = promhttp.Handler()
+
+// recordRequest adds a finished request to the metrics
+func recordRequest(r *http.Request, status int, duration time.Duration) {
+	path := metricsPath(r.URL.Path)
+	requestsTotal.WithLabelValues(path, strconv.Itoa(status)).Inc()
+	requestDuration.WithLabelValues(path).Observe(duration.Seconds())
+}
+
+// metricsPath returns the route a request path belongs to, so that static
+// files and unknown paths do not each add their own series
+func metricsPath(path string) string {
+	switch path {
+	case "/", "/ascii-art", "/ascii-art/reverse", "/download", "/preview", "/decode", "/api/ascii-art", "/api/banners", "/api/banners/validate", "/health", "/version", "/metrics", "/admin/banners", "/upload-banner", "/admin/reload", "/style.css":
+		return path
+	}
+	if strings.HasPrefix(path, "/static/") {
+		return "/static/"
+	}
+	return "other"
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// gzipMinBytes is the smallest response body gzipMiddleware compresses;
+// smaller bodies gain little and are sent as they are
+const gzipMinBytes = 1 << 10
+
+// statusRecorder wraps a ResponseWriter to remember the status code written
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+// WriteHeader records the status code before passing it on
+func (rec *statusRecorder) WriteHeader(statusCode int) {
+	if rec.status == 0 {
+		rec.status = statusCode
+	}
+	rec.ResponseWriter.WriteHeader(statusCode)
+}
+
+// Write records an implicit 200 status when the handler writes without calling WriteHeader
+func (rec *statusRecorder) Write(b []byte) (int, error) {
+	if rec.status == 0 {
+		rec.status = http.StatusOK
+	}
+	return rec.ResponseWriter.Write(b)
+}
+
+// Unwrap exposes the underlying ResponseWriter to http.ResponseController
+func (rec *statusRecorder) Unwrap() http.ResponseWriter {
+	return rec.ResponseWriter
+}
+
+// loggingMiddleware logs the method, path, status and duration of every
+// request and records them in the request metrics
+func loggingMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w}
+		next.ServeHTTP(rec, r)
+		if rec.status == 0 {
+			rec.status = http.StatusOK
+		}
+		duration := time.Since(start)
+		log.Printf("%s %s %d %s", r.Method, r.URL.Path, rec.status, duration)
+		recordRequest(r, rec.status, duration)
+	})
+}
+
+// gzipWriter buffers a response until it reaches gzipMinBytes, then
+// switches to gzip for the rest of it. Responses that end below the
+// threshold are written out unchanged by finish.
+type gzipWriter struct {
+	http.ResponseWriter
+	status int
+	buf    bytes.Buffer
+	gz     *gzip.Writer
+}
+
+// WriteHeader holds the status code back until the encoding is decided
+func (gw *gzipWriter) WriteHeader(statusCode int) {
+	if gw.status == 0 {
+		gw.status = statusCode
+	}
+}
+
+// Write buffers b, or compresses it once the response is large enough
+func (gw *gzipWriter) Write(b []byte) (int, error) {
+	if gw.status == 0 {
+		gw.status = http.StatusOK
+	}
+	if gw.gz != nil {
+		return gw.gz.Write(b)
+	}
+	gw.buf.Write(b)
+	if gw.buf.Len() >= gzipMinBytes && gw.Header().Get("Content-Encoding") == "" {
+		// The body is large enough: send the headers and compress from here on
+		gw.Header().Set("Content-Encoding", "gzip")
+		gw.Header().Del("Content-Length")
+		gw.ResponseWriter.WriteHeader(gw.status)
+		gw.gz = gzip.NewWriter(gw.ResponseWriter)
+		if _, err := gw.gz.Write(gw.buf.Bytes()); err != nil {
+			return 0, err
+		}
+		gw.buf.Reset()
+	}
+	return len(b), nil
+}
+
+// Unwrap exposes the underlying ResponseWriter to http.ResponseController
+func (gw *gzipWriter) Unwrap() http.ResponseWriter {
+	return gw.ResponseWriter
+}
+
+// finish flushes the compressed stream, or writes a small response as is
+func (gw *gzipWriter) finish() error {
+	if gw.gz != nil {
+		return gw.gz.Close()
+	}
+	if gw.status == 0 {
+		return nil
+	}
+	gw.ResponseWriter.WriteHeader(gw.status)
+	_, err := gw.ResponseWriter.Write(gw.buf.Bytes())
+	return err
+}
+
+// gzipMiddleware compresses responses of at least gzipMinBytes for clients
+// that accept gzip
+func gzipMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The response depends on Accept-Encoding whether or not it is compressed
+		w.Header().Add("Vary", "Accept-Encoding")
+		if !acceptsGzip(r.Header.Get("Accept-Encoding")) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		gw := &gzipWriter{ResponseWriter: w}
+		next.ServeHTTP(gw, r)
+		if err := gw.finish(); err != nil {
+			log.Printf("Error writing compressed response: %v", err)
+		}
+	})
+}
+
+// acceptsGzip reports whether an Accept-Encoding header allows gzip,
+// skipping encodings refused with q=0
+func acceptsGzip(header string) bool {
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(part, ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name != "gzip" && name != "*" {
+			continue
+		}
+		q := strings.ReplaceAll(params, " ", "")
+		if q != "q=0" && q != "q=0.0" && q != "q=0.00" && q != "q=0.000" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// gzipResponse serves a body of size bytes through gzipMiddleware with the
+// given Accept-Encoding header
+func gzipResponse(size int, acceptEncoding string) *httptest.ResponseRecorder {
+	handler := gzipMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.WriteHeader(http.StatusCreated)
+		// Write in small pieces so the switch to gzip happens mid-response
+		body := strings.Repeat("a", size)
+		for len(body) > 0 {
+			n := min(len(body), 100)
+			io.WriteString(w, body[:n])
+			body = body[n:]
+		}
+	}))
+	req := httptest.NewRequest("GET", "/", nil)
+	if acceptEncoding != "" {
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestGzipMiddleware(t *testing.T) {
+	tests := []struct {
+		name           string
+		size           int
+		acceptEncoding string
+		wantGzip       bool
+	}{
+		{name: "at the threshold", size: gzipMinBytes, acceptEncoding: "gzip", wantGzip: true},
+		{name: "large", size: 10 * gzipMinBytes, acceptEncoding: "br, gzip;q=0.5", wantGzip: true},
+		{name: "below the threshold", size: gzipMinBytes - 1, acceptEncoding: "gzip", wantGzip: false},
+		{name: "gzip not accepted", size: 10 * gzipMinBytes, acceptEncoding: "", wantGzip: false},
+		{name: "gzip refused", size: 10 * gzipMinBytes, acceptEncoding: "gzip;q=0", wantGzip: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := gzipResponse(tt.size, tt.acceptEncoding)
+			if rec.Code != http.StatusCreated {
+				t.Errorf("status = %d, want %d", rec.Code, http.StatusCreated)
+			}
+			if got := rec.Header().Get("Vary"); got != "Accept-Encoding" {
+				t.Errorf("Vary = %q, want Accept-Encoding", got)
+			}
+			body := io.Reader(rec.Body)
+			encoding := rec.Header().Get("Content-Encoding")
+			if tt.wantGzip {
+				if encoding != "gzip" {
+					t.Fatalf("Content-Encoding = %q, want gzip", encoding)
+				}
+				gz, err := gzip.NewReader(rec.Body)
+				if err != nil {
+					t.Fatal(err)
+				}
+				body = gz
+			} else if encoding != "" {
+				t.Fatalf("Content-Encoding = %q, want none", encoding)
+			}
+			got, err := io.ReadAll(body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != strings.Repeat("a", tt.size) {
+				t.Errorf("body has %d bytes, want %d", len(got), tt.size)
+			}
+		})
+	}
+}
+
+func TestGzipWriterUnwrap(t *testing.T) {
+	rec := httptest.NewRecorder()
+	gw := &gzipWriter{ResponseWriter: rec}
+	if err := http.NewResponseController(gw).Flush(); err != nil {
+		t.Errorf("Flush through the gzip writer: %v", err)
+	}
+	if !rec.Flushed {
+		t.Error("the underlying writer was not flushed")
+	}
+}
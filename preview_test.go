@@ -0,0 +1,62 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestPreview(t *testing.T) {
+	tests := []struct {
+		name       string
+		target     string
+		accept     string
+		wantStatus int
+		wantType   string
+		wantBody   string
+	}{
+		{name: "plain text", target: "/preview?banner=standard", wantStatus: http.StatusOK, wantType: "text/plain; charset=utf-8", wantBody: "/ ____ \\  | |_) | | |____"},
+		{name: "HTML fragment", target: "/preview?banner=standard", accept: "text/html", wantStatus: http.StatusOK, wantType: "text/html; charset=utf-8", wantBody: `<pre class="banner-preview">`},
+		{name: "unknown banner", target: "/preview?banner=nope", wantStatus: http.StatusNotFound, wantBody: "Unsupported banner"},
+		{name: "missing banner", target: "/preview", wantStatus: http.StatusBadRequest, wantBody: "Missing banner"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.target, nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			rec := httptest.NewRecorder()
+			Serverouter(rec, req)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("GET %s returned %d, want %d", tt.target, rec.Code, tt.wantStatus)
+			}
+			if tt.wantType != "" && rec.Header().Get("Content-Type") != tt.wantType {
+				t.Errorf("Content-Type = %q, want %q", rec.Header().Get("Content-Type"), tt.wantType)
+			}
+			if !strings.Contains(rec.Body.String(), tt.wantBody) {
+				t.Errorf("body does not contain %q:\n%s", tt.wantBody, rec.Body)
+			}
+		})
+	}
+}
+
+func TestPreviewCache(t *testing.T) {
+	first, err := bannerPreview("shadow")
+	if err != nil {
+		t.Fatalf("bannerPreview returned error: %v", err)
+	}
+	// Later requests are served from the cache without rendering again
+	previewCacheMu.Lock()
+	previewCache["shadow"] = "cached"
+	previewCacheMu.Unlock()
+	if got, _ := bannerPreview("shadow"); got != "cached" {
+		t.Errorf("bannerPreview = %q, want the cached sample", got)
+	}
+	// Rescanning the banners clears the cache
+	setSupportedBanners(availableBanners())
+	if got, _ := bannerPreview("shadow"); got != first {
+		t.Errorf("bannerPreview after rescan = %q, want %q", got, first)
+	}
+}
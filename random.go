@@ -0,0 +1,53 @@
+package main
+
+import (
+	"math/rand/v2"
+	"sync"
+
+	"ASCII/asciiart"
+)
+
+// randomBanner is the banner name that picks one of the available banners per request
+const randomBanner = "random"
+
+// bannerPicker chooses one banner from a non-empty list
+type bannerPicker interface {
+	Pick(banners []string) string
+}
+
+// randPicker picks banners uniformly at random from a seeded source
+type randPicker struct {
+	mu  sync.Mutex // rand.Rand is not safe for concurrent use
+	rng *rand.Rand
+}
+
+// newRandPicker returns a picker whose choices are determined by seed
+func newRandPicker(seed uint64) *randPicker {
+	return &randPicker{rng: rand.New(rand.NewPCG(seed, seed))}
+}
+
+// Pick returns one of banners chosen uniformly at random
+func (p *randPicker) Pick(banners []string) string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return banners[p.rng.IntN(len(banners))]
+}
+
+// picker chooses the banner for banner=random requests
+var picker bannerPicker = newRandPicker(rand.Uint64())
+
+// pickRandomBanner returns one of the available banners chosen by picker,
+// leaving out banners that fail to load, or randomBanner itself when none
+// load
+func pickRandomBanner() string {
+	var loaded []string
+	for _, banner := range availableBanners() {
+		if _, err := asciiart.LoadBanner(banner); err == nil {
+			loaded = append(loaded, banner)
+		}
+	}
+	if len(loaded) == 0 {
+		return randomBanner
+	}
+	return picker.Pick(loaded)
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// rateLimitedPaths are the routes that render or decode art and so count
+// against the rate limit
+var rateLimitedPaths = map[string]bool{
+	"/ascii-art":         true,
+	"/download":          true,
+	"/api/ascii-art":     true,
+	"/preview":           true,
+	"/decode":            true,
+	"/ascii-art/reverse": true,
+}
+
+// rendersArt reports whether a request generates art: any request to the
+// rate limited routes, and home page links that show art
+func rendersArt(r *http.Request) bool {
+	return rateLimitedPaths[r.URL.Path] || r.URL.Path == "/" && homeRendersArt(r)
+}
+
+// redirectGrace is how long a client has to follow a redirect for free
+const redirectGrace = time.Minute
+
+// rateLimiter is a token bucket per client IP. Each client may make up to
+// perMinute requests in a burst, and regains one request every
+// 60/perMinute seconds. A redirect from a request that was charged, such as
+// the one after a form submission, may be followed once for free.
+type rateLimiter struct {
+	mu         sync.Mutex
+	perMinute  int
+	trustProxy bool // take the client IP from X-Forwarded-For
+	buckets    map[string]*tokenBucket
+	redirects  map[string]time.Time // expiry of each prepaid redirect, keyed by client and URL
+	lastPrune  time.Time
+}
+
+// tokenBucket holds the requests a client has left as of last
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// newRateLimiter creates a limiter allowing perMinute requests per client IP
+func newRateLimiter(perMinute int, trustProxy bool) *rateLimiter {
+	return &rateLimiter{perMinute: perMinute, trustProxy: trustProxy, buckets: make(map[string]*tokenBucket), redirects: make(map[string]time.Time)}
+}
+
+// prepay lets the client follow a redirect to target once without taking a token
+func (l *rateLimiter) prepay(client, target string, now time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.redirects[client+" "+target] = now.Add(redirectGrace)
+}
+
+// usePrepaid reports whether the client has a prepaid redirect to target,
+// using it up
+func (l *rateLimiter) usePrepaid(client, target string, now time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	key := client + " " + target
+	expiry, ok := l.redirects[key]
+	delete(l.redirects, key)
+	return ok && now.Before(expiry)
+}
+
+// allow takes a token from the client's bucket. When the bucket is empty it
+// reports false and how long until the next token arrives.
+func (l *rateLimiter) allow(client string, now time.Time) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	perSecond := float64(l.perMinute) / 60
+	l.prune(now, perSecond)
+
+	b, ok := l.buckets[client]
+	if !ok {
+		b = &tokenBucket{tokens: float64(l.perMinute), last: now}
+		l.buckets[client] = b
+	}
+	// Refill the bucket for the time since the client's last request
+	b.tokens = math.Min(float64(l.perMinute), b.tokens+now.Sub(b.last).Seconds()*perSecond)
+	b.last = now
+	if b.tokens < 1 {
+		return false, time.Duration((1 - b.tokens) / perSecond * float64(time.Second))
+	}
+	b.tokens--
+	return true, 0
+}
+
+// prune forgets clients whose buckets have refilled, at most once a minute,
+// so the map does not grow with every address ever seen
+func (l *rateLimiter) prune(now time.Time, perSecond float64) {
+	if now.Sub(l.lastPrune) < time.Minute {
+		return
+	}
+	l.lastPrune = now
+	for client, b := range l.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*perSecond >= float64(l.perMinute) {
+			delete(l.buckets, client)
+		}
+	}
+	for key, expiry := range l.redirects {
+		if !now.Before(expiry) {
+			delete(l.redirects, key)
+		}
+	}
+}
+
+// clientIP returns the address a request is rate limited by: the first
+// X-Forwarded-For entry when running behind a trusted proxy, otherwise the
+// IP of the connection
+func (l *rateLimiter) clientIP(r *http.Request) string {
+	if l.trustProxy {
+		if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+			first, _, _ := strings.Cut(forwarded, ",")
+			return strings.TrimSpace(first)
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// rateLimitMiddleware rejects requests that generate art with 429 once a
+// client runs out of requests. Following the redirect of a form submission
+// does not count as a second request.
+func rateLimitMiddleware(l *rateLimiter, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		client := l.clientIP(r)
+		if !rendersArt(r) || r.Method == "GET" && l.usePrepaid(client, r.URL.RequestURI(), time.Now()) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if ok, wait := l.allow(client, time.Now()); !ok {
+			seconds := int(math.Ceil(wait.Seconds()))
+			w.Header().Set("Retry-After", strconv.Itoa(seconds))
+			msg := "Too many requests: please wait " + strconv.Itoa(seconds) + " seconds and try again."
+			if strings.HasPrefix(r.URL.Path, "/api/") {
+				renderJSONError(w, msg, http.StatusTooManyRequests)
+			} else {
+				renderError(w, msg, http.StatusTooManyRequests)
+			}
+			return
+		}
+		next.ServeHTTP(w, r)
+		if location := w.Header().Get("Location"); location != "" {
+			l.prepay(client, location, time.Now())
+		}
+	})
+}
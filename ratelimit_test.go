@@ -0,0 +1,104 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// limitedStatuses sends each request through a limiter of perMinute
+// requests around the router and returns the status codes
+func limitedStatuses(perMinute int, requests ...*http.Request) []int {
+	handler := rateLimitMiddleware(newRateLimiter(perMinute, false), http.HandlerFunc(Serverouter))
+	statuses := make([]int, len(requests))
+	for i, req := range requests {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		statuses[i] = rec.Code
+	}
+	return statuses
+}
+
+func TestRateLimitHomeLinks(t *testing.T) {
+	var requests []*http.Request
+	for range 6 {
+		requests = append(requests, httptest.NewRequest("GET", "/?text=hi&banner=standard", nil))
+	}
+	statuses := limitedStatuses(4, requests...)
+	for i, status := range statuses {
+		want := http.StatusOK
+		if i >= 4 {
+			want = http.StatusTooManyRequests
+		}
+		if status != want {
+			t.Errorf("request %d returned %d, want %d", i+1, status, want)
+		}
+	}
+}
+
+func TestRateLimitPlainHomePage(t *testing.T) {
+	var requests []*http.Request
+	for range 6 {
+		requests = append(requests, httptest.NewRequest("GET", "/?text=hi", nil))
+	}
+	for i, status := range limitedStatuses(4, requests...) {
+		if status != http.StatusOK {
+			t.Errorf("request %d returned %d, want %d", i+1, status, http.StatusOK)
+		}
+	}
+}
+
+// submitForm posts text to /ascii-art through handler and follows the
+// redirect, returning both status codes
+func submitForm(handler http.Handler, text string) (int, int) {
+	req := httptest.NewRequest("POST", "/ascii-art", strings.NewReader("banner=standard&text="+text))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	post := httptest.NewRecorder()
+	handler.ServeHTTP(post, req)
+	if post.Code != http.StatusSeeOther {
+		return post.Code, 0
+	}
+	get := httptest.NewRecorder()
+	handler.ServeHTTP(get, httptest.NewRequest("GET", post.Header().Get("Location"), nil))
+	return post.Code, get.Code
+}
+
+func TestRateLimitFormRedirects(t *testing.T) {
+	handler := rateLimitMiddleware(newRateLimiter(4, false), http.HandlerFunc(Serverouter))
+	// Each submission costs one request, including its redirect
+	for i := range 4 {
+		if post, get := submitForm(handler, "hi"); post != http.StatusSeeOther || get != http.StatusOK {
+			t.Fatalf("submission %d returned %d then %d, want %d then %d", i+1, post, get, http.StatusSeeOther, http.StatusOK)
+		}
+	}
+	if post, _ := submitForm(handler, "hi"); post != http.StatusTooManyRequests {
+		t.Errorf("submission 5 returned %d, want %d", post, http.StatusTooManyRequests)
+	}
+}
+
+func TestRateLimitRedirectUsedOnce(t *testing.T) {
+	handler := rateLimitMiddleware(newRateLimiter(1, false), http.HandlerFunc(Serverouter))
+	if post, get := submitForm(handler, "hi"); post != http.StatusSeeOther || get != http.StatusOK {
+		t.Fatalf("submission returned %d then %d, want %d then %d", post, get, http.StatusSeeOther, http.StatusOK)
+	}
+	// Reloading the result is a new request
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/ascii-art?banner=standard&text=hi", nil))
+	if rec.Code != http.StatusTooManyRequests {
+		t.Errorf("reload returned %d, want %d", rec.Code, http.StatusTooManyRequests)
+	}
+}
+
+func TestRateLimitOtherRoutes(t *testing.T) {
+	for _, target := range []string{"/preview?banner=standard", "/decode", "/ascii-art/reverse"} {
+		method := "POST"
+		if strings.HasPrefix(target, "/preview") {
+			method = "GET"
+		}
+		statuses := limitedStatuses(1, httptest.NewRequest(method, target, nil), httptest.NewRequest(method, target, nil))
+		if statuses[0] == http.StatusTooManyRequests || statuses[1] != http.StatusTooManyRequests {
+			t.Errorf("%s %s returned %v, want the second request limited", method, target, statuses)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// startServer serves the router with the timeouts of cfg on a free port and
+// returns its address
+func startServer(t *testing.T, cfg config) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := newServer(cfg, http.HandlerFunc(Serverouter))
+	go server.Serve(listener)
+	t.Cleanup(func() { server.Close() })
+	return listener.Addr().String()
+}
+
+// sendStalled writes the start of a request and returns the connection
+// without finishing it
+func sendStalled(t *testing.T, addr, partial string) net.Conn {
+	t.Helper()
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	if _, err := io.WriteString(conn, partial); err != nil {
+		t.Fatal(err)
+	}
+	// Give up well after the server should have
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	return conn
+}
+
+func TestStalledRequests(t *testing.T) {
+	cfg := config{ReadHeaderTimeout: 100 * time.Millisecond, ReadTimeout: 200 * time.Millisecond, WriteTimeout: time.Second, IdleTimeout: time.Second, MaxHeaderBytes: 1 << 20}
+	addr := startServer(t, cfg)
+
+	t.Run("stalled headers", func(t *testing.T) {
+		start := time.Now()
+		conn := sendStalled(t, addr, "GET / HTTP/1.1\r\nHost: localhost\r\n")
+		if _, err := io.ReadAll(conn); err != nil {
+			t.Fatalf("connection was not closed: %v", err)
+		}
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("connection closed after %s, want within the read header timeout", elapsed)
+		}
+	})
+
+	t.Run("stalled body", func(t *testing.T) {
+		start := time.Now()
+		conn := sendStalled(t, addr, "POST /ascii-art HTTP/1.1\r\nHost: localhost\r\nContent-Type: application/x-www-form-urlencoded\r\nContent-Length: 100\r\n\r\ntext=Hi")
+		resp, err := http.ReadResponse(bufio.NewReader(conn), nil)
+		if err != nil {
+			t.Fatalf("reading response: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusRequestTimeout {
+			t.Errorf("stalled body returned %d, want %d", resp.StatusCode, http.StatusRequestTimeout)
+		}
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("response came after %s, want within the read timeout", elapsed)
+		}
+	})
+}
@@ -0,0 +1,24 @@
+// Show a sample of the selected banner under the banner dropdown
+document.addEventListener("DOMContentLoaded", function () {
+  var select = document.getElementById("banner");
+  var preview = document.getElementById("banner-preview");
+  if (!select || !preview) {
+    return;
+  }
+  function showPreview() {
+    // Random has no single banner to preview
+    if (select.value === "random") {
+      preview.innerHTML = "";
+      return;
+    }
+    fetch("/preview?banner=" + encodeURIComponent(select.value), { headers: { Accept: "text/html" } })
+      .then(function (response) {
+        return response.ok ? response.text() : "";
+      })
+      .then(function (html) {
+        preview.innerHTML = html;
+      });
+  }
+  select.addEventListener("change", showPreview);
+  showPreview();
+});
@@ -0,0 +1,28 @@
+package main
+
+import "net/http"
+
+// Build information, set at build time with
+// -ldflags "-X main.version=... -X main.commit=... -X main.buildTime=..."
+var (
+	version   = "dev"
+	commit    = "dev"
+	buildTime = "dev"
+)
+
+// versionResponse is the JSON body describing the running build
+type versionResponse struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildTime string `json:"buildTime"`
+}
+
+// versionHandler reports the version, commit and build time of the running binary
+func versionHandler(w http.ResponseWriter, r *http.Request) {
+	// Check if the request method is GET
+	if r.Method != "GET" {
+		renderJSONMethodNotAllowed(w, "GET")
+		return
+	}
+	renderJSON(w, versionResponse{Version: version, Commit: commit, BuildTime: buildTime}, http.StatusOK)
+}